@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"strings"
 	"runtime/pprof"
@@ -11,13 +12,17 @@ import (
 	"github.com/hunterloftis/pbr/pkg/format/obj"
 	"github.com/hunterloftis/pbr/pkg/geom"
 	"github.com/hunterloftis/pbr/pkg/material"
+	"github.com/hunterloftis/pbr/pkg/medium"
 	"github.com/hunterloftis/pbr/pkg/render"
 	"github.com/hunterloftis/pbr/pkg/rgb"
 	"github.com/hunterloftis/pbr/pkg/surface"
+	"github.com/hunterloftis/pbr/pkg/texture"
 )
 
 var materials = map[string]surface.Material{
 	"gold":    material.Gold(0.03, 0.9),
+	"silver":  material.Silver(0.05),
+	"chrome":  material.Chrome(0.01),
 	"mirror":  material.Mirror(0.001),
 	"glass":   material.Glass(0.03),
 	"flat":    material.Plastic(1, 1, 1, 0.5),
@@ -54,11 +59,21 @@ func run(o *Options) error {
 		defer stopProfile(f)
 	}
 
+	texture.Files.Budget = o.Textures << 20
 	mesh, err := obj.ReadFile(o.Scene, true)
 	if err != nil {
 		return err
 	}
 
+	if o.Crease != nil {
+		mesh.SetCrease(*o.Crease * math.Pi / 180)
+	}
+	if o.Normals > 0 {
+		mesh.ComputeNormals(o.Normals * math.Pi / 180)
+	}
+	if o.Subdivide > 0 {
+		mesh.Subdivide(o.Subdivide)
+	}
 	if o.Scale != nil {
 		mesh.Scale(*o.Scale)
 	}
@@ -79,6 +94,12 @@ func run(o *Options) error {
 	camera.FStop = o.FStop
 	camera.Focus = o.Focus
 
+	if o.Displace > 0 {
+		angle := camera.PixelAngle(float64(o.Width), float64(o.Height))
+		mesh.DisplaceFrom(*o.From, o.Displace*angle)
+		bounds, surfaces = mesh.Bounds()
+	}
+
 	if o.Verbose || o.Info {
 		printInfo(bounds, len(surfaces), camera)
 		if o.Info {
@@ -94,10 +115,10 @@ func run(o *Options) error {
 	}
 
 	if o.Floor > 0 {
-		floor := surface.UnitCube(material.Plastic(o.FloorColor.X, o.FloorColor.Y, o.FloorColor.Z, o.FloorRough))
+		floor := surface.UnitRect(material.Plastic(o.FloorColor.X, o.FloorColor.Y, o.FloorColor.Z, o.FloorRough))
 		dims := bounds.Max.Minus(bounds.Min).Scaled(o.Floor)
-		floor.Shift(geom.Vec{bounds.Center.X, bounds.Min.Y - dims.Y*0.5, bounds.Center.Z})
-		floor.Scale(geom.Vec{dims.X, dims.Y, dims.Z})
+		floor.Shift(geom.Vec{bounds.Center.X, bounds.Min.Y, bounds.Center.Z})
+		floor.Scale(geom.Vec{dims.X, 1, dims.Z})
 		surfaces = append(surfaces, floor)
 	}
 
@@ -109,6 +130,9 @@ func run(o *Options) error {
 
 	tree := surface.NewTree(surfaces...)
 	scene := render.NewScene(camera, tree, environment)
+	if o.Fog > 0 {
+		scene.Medium = &medium.Homogeneous{Albedo: rgb.White, MeanFreePath: rgb.Energy{o.Fog, o.Fog, o.Fog}}
+	}
 
 	fmt.Println("Surfaces:", len(surfaces))
 	return render.Iterative(scene, o.Out, o.Width, o.Height, o.Bounce, !o.Indirect)
@@ -17,7 +17,13 @@ type Options struct {
 	Info     bool    `help:"output scene information and exit"`
 	Frames   float64 `arg:"-f" help:"number of frames at which to exit"`
 	Time     float64 `arg:"-t" help:"time to run before exiting (seconds)"`
-	Material string  `help:"override material (glass, gold, mirror, plastic)"`
+	Material string  `help:"override material (chrome, glass, gold, mirror, plastic, silver)"`
+
+	Normals   float64  `help:"regenerate vertex normals, smoothing faces that meet at up to this angle (in degrees)"`
+	Subdivide int      `help:"levels of mesh subdivision"`
+	Crease    *float64 `help:"angle between faces (in degrees) above which smoothing groups and subdivision keep edges sharp (default 180)"`
+	Displace  float64  `help:"apply displacement maps, tessellated to this edge length in pixels"`
+	Textures  int      `help:"texture memory budget in megabytes, beyond which detail is discarded (0 for no limit)"`
 
 	Width  int       `arg:"-w" help:"rendering width in pixels"`
 	Height int       `arg:"-h" help:"rendering height in pixels"`
@@ -45,6 +51,7 @@ type Options struct {
 	Floor      float64     `help:"size of the floor relative to the scene mesh"`
 	FloorColor *rgb.Energy `help:"the color of the floor"`
 	FloorRough float64     `help:"roughness of the floor"`
+	Fog        float64     `help:"fill the scene's bounds with fog through which light travels this far between scattering, on average"`
 	Sun        *geom.Vec   `help:"position of a daylight emitter"`
 	SunSize    float64     `help:"size of the sun"`
 }
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/hunterloftis/pbr/pkg/camera"
+	"github.com/hunterloftis/pbr/pkg/env"
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/material"
+	"github.com/hunterloftis/pbr/pkg/render"
+	"github.com/hunterloftis/pbr/pkg/sdf"
+	"github.com/hunterloftis/pbr/pkg/surface"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "\nError: %v\n", err)
+	}
+}
+
+func run() error {
+	white := material.Plastic(1, 1, 1, 0.3)
+	red := material.Plastic(1, 0.05, 0.05, 0.05)
+	blue := material.Plastic(0.05, 0.05, 1, 0.05)
+	gold := material.Gold(0.05, 1)
+	glass := material.Glass(0.0001)
+	grid := material.NewGrid(white, blue, 2, 0.02)
+	panel := material.Light(3000, 3000, 3000)
+
+	box := sdf.SmoothSubtract(0.02, sdf.RoundBox(geom.Vec{1, 1, 1}, 0.15), sdf.Sphere(0.6))
+	hollow := surface.NewDifference(
+		surface.UnitCube(glass).Shift(geom.Vec{-0.5, 0.1, 0.35}).Scale(geom.Vec{0.2, 0.2, 0.2}),
+		surface.UnitSphere(glass).Shift(geom.Vec{-0.5, 0.1, 0.35}).Scale(geom.Vec{0.24, 0.24, 0.24}),
+	)
+	unit := geom.NewBounds(geom.Vec{-0.5, -0.5, -0.5}, geom.Vec{0.5, 0.5, 0.5})
+
+	sky := env.NewFlat(20, 25, 30)
+	cam := camera.NewSLR()
+	cam.MoveTo(geom.Vec{0, 0.6, 1.6}).LookAt(geom.Vec{0, 0.15, 0})
+	surf := surface.NewTree(
+		surface.UnitPlane(grid),
+		surface.UnitRect(panel).Shift(geom.Vec{0, 2, 0}).Rotate(geom.Vec{math.Pi, 0, 0}),
+		surface.UnitCylinder(red).Shift(geom.Vec{-0.45, 0.15, 0}).Scale(geom.Vec{0.2, 0.3, 0.2}),
+		surface.UnitCone(gold).Shift(geom.Vec{-0.15, 0.15, -0.2}).Scale(geom.Vec{0.2, 0.3, 0.2}),
+		surface.UnitTorus(0.15, glass).Shift(geom.Vec{0.15, 0.06, 0.1}).Scale(geom.Vec{0.3, 0.3, 0.3}),
+		surface.UnitDisk(gold).Shift(geom.Vec{0.45, 0.15, -0.1}).Rotate(geom.Vec{0.5 * math.Pi, 0, 0}).Scale(geom.Vec{0.25, 1, 0.25}),
+		surface.UnitSphere(blue).Shift(geom.Vec{0.15, 0.08, 0.1}).Scale(geom.Vec{0.12, 0.12, 0.12}),
+		hollow,
+		surface.NewSDF(box, unit, white).Shift(geom.Vec{0.2, 0.1, -0.4}).Rotate(geom.Vec{0, 0.2, 0}).Scale(geom.Vec{0.2, 0.2, 0.2}),
+	)
+	scene := render.NewScene(cam, surf, sky)
+
+	return render.Iterative(scene, "primitives.png", 800, 450, 6, true)
+}
@@ -0,0 +1,84 @@
+package bsdf
+
+import (
+	"math"
+
+	"github.com/hunterloftis/pbr/pkg/render"
+)
+
+// Boundary is a BSDF on the surface of a volume, such as a rough dielectric around a volume of scattering marble,
+// or glass around a liquid.
+// Light that it transmits inward travels through the Medium, if there is one, until it leaves.
+// Where volumes overlap, the one with the highest Rank fills the overlap.
+type Boundary struct {
+	render.BSDF
+	Medium render.Medium
+	Rank   int // its Priority, such as 1 for a glass around water of Rank 0
+}
+
+func (b Boundary) Interior() render.Medium {
+	return b.Medium
+}
+
+func (b Boundary) Priority() int {
+	return b.Rank
+}
+
+// IOR returns the refractive index inside the boundary, which is 1 unless the BSDF refracts.
+func (b Boundary) IOR() float64 {
+	if d, ok := b.BSDF.(dielectric); ok {
+		return d.interior()
+	}
+	return 1
+}
+
+// Outside returns the Boundary with a refractive index of exterior beyond it.
+// The BSDF refracts by the ratio of the indices, and ignores light altogether if they match.
+func (b Boundary) Outside(exterior float64) render.BSDF {
+	if d, ok := b.BSDF.(dielectric); ok {
+		b.BSDF = d.within(exterior)
+	}
+	return b
+}
+
+func (b Boundary) Invisible() bool {
+	i, ok := b.BSDF.(render.Invisible)
+	return ok && i.Invisible()
+}
+
+func (b Boundary) Delta() bool {
+	d, ok := b.BSDF.(render.Delta)
+	return ok && d.Delta()
+}
+
+// Oriented returns the Boundary with its BSDF's anisotropic highlight, if any, rotated by angle.
+func (b Boundary) Oriented(angle float64) render.BSDF {
+	if o, ok := b.BSDF.(oriented); ok {
+		b.BSDF = o.Oriented(angle)
+	}
+	return b
+}
+
+// oriented is a BSDF with an anisotropic highlight.
+type oriented interface {
+	Oriented(angle float64) render.BSDF
+}
+
+// dielectric is a BSDF that refracts light through a boundary between refractive indices.
+type dielectric interface {
+	interior() float64                   // returns the refractive index inside the boundary
+	within(exterior float64) render.BSDF // returns the BSDF with a refractive index of exterior beyond the boundary
+}
+
+// vacuum returns ior, or the refractive index of a vacuum if ior is unset.
+func vacuum(ior float64) float64 {
+	if ior <= 0 {
+		return 1
+	}
+	return ior
+}
+
+// matched returns whether light passes between refractive indices a and b without refracting or reflecting.
+func matched(a, b float64) bool {
+	return math.Abs(a-vacuum(b)) < 1e-4
+}
@@ -0,0 +1,109 @@
+package bsdf
+
+import (
+	"math/rand"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/render"
+	"github.com/hunterloftis/pbr/pkg/rgb"
+)
+
+// Smooth is the Roughness below which microfacet BSDFs become numerically unstable
+// and should be replaced by the perfectly smooth Mirror and Glass.
+const Smooth = 0.03
+
+// Mirror is a perfectly smooth reflector.
+type Mirror struct {
+	Specular   rgb.Energy
+	Multiplier float64
+	Fresnel    Fresnel // replaces Schlick's approximation with Specular, if present
+}
+
+func (m Mirror) Sample(wo geom.Dir, rnd *rand.Rand) (geom.Dir, float64, bool) {
+	return mirror(wo), 1, false
+}
+
+func (m Mirror) PDF(wi, wo geom.Dir) float64 {
+	if !wi.Equals(mirror(wo)) {
+		return 0
+	}
+	return 1
+}
+
+func (m Mirror) Eval(wi, wo geom.Dir) rgb.Energy {
+	if wo.Y <= 0 || !wi.Equals(mirror(wo)) {
+		return rgb.Black
+	}
+	return fresnel(m.Fresnel, m.Specular).Reflectance(wo.Y).Scaled(m.Multiplier)
+}
+
+func (m Mirror) Delta() bool {
+	return true
+}
+
+// Glass is a perfectly smooth dielectric boundary that reflects or refracts light in proportion to its Fresnel reflectance.
+// wo may be on either side of the boundary; the outside is toward the normal.
+type Glass struct {
+	Specular   float64
+	Multiplier float64
+	Exterior   float64 // refractive index beyond the boundary, or zero for a vacuum
+}
+
+func (g Glass) Sample(wo geom.Dir, rnd *rand.Rand) (geom.Dir, float64, bool) {
+	lo, flip := facing(wo)
+	eta := g.eta(wo)
+	f := fresnelDielectric(lo.Y, eta)
+	if rnd.Float64() < f {
+		return mirror(wo), f, false
+	}
+	li, _ := refracted(lo, geom.Up, eta)
+	return flip(li), 1 - f, false
+}
+
+// PDF returns the probability of sampling wi, which is zero outside of the directions of reflection and refraction.
+func (g Glass) PDF(wi, wo geom.Dir) float64 {
+	lo, flip := facing(wo)
+	eta := g.eta(wo)
+	f := fresnelDielectric(lo.Y, eta)
+	if wi.Equals(mirror(wo)) {
+		return f
+	}
+	if li, ok := refracted(lo, geom.Up, eta); ok && wi.Equals(flip(li)) {
+		return 1 - f
+	}
+	return 0
+}
+
+func (g Glass) Eval(wi, wo geom.Dir) rgb.Energy {
+	return rgb.White.Scaled(g.PDF(wi, wo) * g.Multiplier)
+}
+
+func (g Glass) Delta() bool {
+	return true
+}
+
+// IOR returns the refractive index inside the boundary.
+func (g Glass) IOR() float64 {
+	return fresnelToRefractiveIndex(g.Specular)
+}
+
+func (g Glass) interior() float64 {
+	return g.IOR()
+}
+
+func (g Glass) within(exterior float64) render.BSDF {
+	if matched(g.IOR(), exterior) {
+		return Ignore{}
+	}
+	g.Exterior = exterior
+	return g
+}
+
+func (g Glass) eta(wo geom.Dir) float64 {
+	return Transmit{Specular: g.Specular, Exterior: g.Exterior}.eta(wo)
+}
+
+// mirror reflects w about the normal.
+func mirror(w geom.Dir) geom.Dir {
+	return geom.Dir{-w.X, w.Y, -w.Z}
+}
@@ -0,0 +1,108 @@
+package bsdf
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+)
+
+// minAlpha keeps nearly smooth distributions from becoming numerically unstable.
+const minAlpha = 0.001
+
+// distribution is an anisotropic GGX (Trowbridge-Reitz) distribution of microfacet normals,
+// with roughness ax along the tangent space X axis and az along Z.
+// https://jcgt.org/published/0003/02/03/paper.pdf
+type distribution struct {
+	ax, az float64
+}
+
+// newDistribution returns the distribution for a perceptual roughness and anisotropy, both 0-1.
+// https://media.disneyanimation.com/uploads/production/publication_asset/48/asset/s2012_pbs_disney_brdf_notes_v3.pdf
+func newDistribution(roughness, anisotropy float64) distribution {
+	return anisotropic(roughness*roughness, anisotropy)
+}
+
+// anisotropic returns the distribution with alpha a, stretched along X and squeezed along Z by anisotropy.
+func anisotropic(a, anisotropy float64) distribution {
+	aspect := math.Sqrt(1 - 0.9*anisotropy)
+	return distribution{
+		ax: math.Max(minAlpha, a/aspect),
+		az: math.Max(minAlpha, a*aspect),
+	}
+}
+
+// aligned rotates w from tangent space into the frame of a highlight rotated by angle.
+func aligned(w geom.Dir, angle float64) geom.Dir {
+	sin, cos := math.Sincos(angle)
+	return geom.Dir{w.X*cos + w.Z*sin, w.Y, w.Z*cos - w.X*sin}
+}
+
+// unaligned rotates w from the frame of a highlight rotated by angle into tangent space.
+func unaligned(w geom.Dir, angle float64) geom.Dir {
+	sin, cos := math.Sincos(angle)
+	return geom.Dir{w.X*cos - w.Z*sin, w.Y, w.Z*cos + w.X*sin}
+}
+
+// alpha returns the isotropic alpha with the same area of highlight.
+func (g distribution) alpha() float64 {
+	return math.Sqrt(g.ax * g.az)
+}
+
+// d returns the density of microfacets with normal wm.
+func (g distribution) d(wm geom.Dir) float64 {
+	x, z := wm.X/g.ax, wm.Z/g.az
+	e := x*x + z*z + wm.Y*wm.Y
+	return 1 / (math.Pi * g.ax * g.az * e * e)
+}
+
+// lambda is Smith's auxiliary function for direction w.
+func (g distribution) lambda(w geom.Dir) float64 {
+	if w.Y == 0 {
+		return math.Inf(1)
+	}
+	t := (g.ax*g.ax*w.X*w.X + g.az*g.az*w.Z*w.Z) / (w.Y * w.Y)
+	return (math.Sqrt(1+t) - 1) / 2
+}
+
+// g1 returns the fraction of microfacets visible from w.
+func (g distribution) g1(w geom.Dir) float64 {
+	return 1 / (1 + g.lambda(w))
+}
+
+// g returns the fraction of microfacets visible from both wo and wi (height-correlated masking and shadowing).
+func (g distribution) g(wo, wi geom.Dir) float64 {
+	return 1 / (1 + g.lambda(wo) + g.lambda(wi))
+}
+
+// sample returns a microfacet normal from the distribution of normals visible from wo.
+// https://jcgt.org/published/0007/04/01/paper.pdf
+func (g distribution) sample(wo geom.Dir, rnd *rand.Rand) geom.Dir {
+	return g.sampleAt(wo, rnd.Float64(), rnd.Float64())
+}
+
+// sampleAt returns the visible microfacet normal at u1, u2 in the unit square.
+func (g distribution) sampleAt(wo geom.Dir, u1, u2 float64) geom.Dir {
+	// The paper's z axis is up, where ours is y.
+	v, _ := geom.Vec{g.ax * wo.X, g.az * wo.Z, wo.Y}.Unit()
+	t1 := geom.Vec{1, 0, 0}
+	if l := math.Sqrt(v.X*v.X + v.Y*v.Y); l > 0 {
+		t1 = geom.Vec{-v.Y / l, v.X / l, 0}
+	}
+	t2 := geom.Vec(v).Cross(t1)
+	r, phi := math.Sqrt(u1), 2*math.Pi*u2
+	p1, p2 := r*math.Cos(phi), r*math.Sin(phi)
+	s := 0.5 * (1 + v.Z)
+	p2 = (1-s)*math.Sqrt(1-p1*p1) + s*p2
+	n := t1.Scaled(p1).Plus(t2.Scaled(p2)).Plus(v.Scaled(math.Sqrt(math.Max(0, 1-p1*p1-p2*p2))))
+	wm, _ := geom.Vec{g.ax * n.X, math.Max(1e-6, n.Z), g.az * n.Y}.Unit()
+	return wm
+}
+
+// pdf returns the probability density that sample returns wm.
+func (g distribution) pdf(wo, wm geom.Dir) float64 {
+	if wo.Y <= 0 {
+		return 0
+	}
+	return g.g1(wo) * math.Max(0, wo.Dot(wm)) * g.d(wm) / wo.Y
+}
@@ -0,0 +1,136 @@
+package bsdf
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/hunterloftis/pbr/pkg/rgb"
+)
+
+// Fresnel returns the fraction of light reflected by a surface,
+// given the cosine of the angle between the light and the surface (or microfacet) normal.
+type Fresnel interface {
+	Reflectance(cos float64) rgb.Energy
+}
+
+// Schlick is Schlick's approximation of Fresnel, from the reflectance at normal incidence.
+type Schlick rgb.Energy
+
+func (s Schlick) Reflectance(cos float64) rgb.Energy {
+	return rgb.Energy{
+		X: fresnelSchlick(cos, s.X),
+		Y: fresnelSchlick(cos, s.Y),
+		Z: fresnelSchlick(cos, s.Z),
+	}
+}
+
+// fresnel returns f, or Schlick's approximation with specular if f is nil.
+func fresnel(f Fresnel, specular rgb.Energy) Fresnel {
+	if f == nil {
+		return Schlick(specular)
+	}
+	return f
+}
+
+// Conductor is the Fresnel reflectance of a metal with the complex refractive index Eta + iK in each channel.
+// https://www.pbr-book.org/3ed-2018/Reflection_Models/Specular_Reflection_and_Transmission#FresnelReflectance
+type Conductor struct {
+	Eta, K rgb.Energy
+}
+
+// EdgeTint returns the Conductor that reflects color at normal incidence and tends toward edge at grazing angles.
+// http://jcgt.org/published/0003/04/03/paper.pdf
+func EdgeTint(color, edge rgb.Energy) Conductor {
+	eta, k := edgeTint(color.X, edge.X)
+	c := Conductor{Eta: rgb.Energy{X: eta}, K: rgb.Energy{X: k}}
+	c.Eta.Y, c.K.Y = edgeTint(color.Y, edge.Y)
+	c.Eta.Z, c.K.Z = edgeTint(color.Z, edge.Z)
+	return c
+}
+
+func edgeTint(r, g float64) (eta, k float64) {
+	r = math.Max(0, math.Min(0.99, r))
+	sqrt := math.Sqrt(r)
+	eta = g*(1-r)/(1+r) + (1-g)*(1+sqrt)/(1-sqrt)
+	k = math.Sqrt(math.Max(0, (r*(eta+1)*(eta+1)-(eta-1)*(eta-1))/(1-r)))
+	return eta, k
+}
+
+func (c Conductor) Reflectance(cos float64) rgb.Energy {
+	return rgb.Energy{
+		X: fresnelConductor(cos, c.Eta.X, c.K.X),
+		Y: fresnelConductor(cos, c.Eta.Y, c.K.Y),
+		Z: fresnelConductor(cos, c.Eta.Z, c.K.Z),
+	}
+}
+
+func fresnelConductor(cos, eta, k float64) float64 {
+	cos = math.Max(0, math.Min(1, cos))
+	cos2 := cos * cos
+	sin2 := 1 - cos2
+	eta2, k2 := eta*eta, k*k
+	t0 := eta2 - k2 - sin2
+	a2b2 := math.Sqrt(t0*t0 + 4*eta2*k2)
+	t1 := a2b2 + cos2
+	a := math.Sqrt(math.Max(0, 0.5*(a2b2+t0)))
+	t2 := 2 * cos * a
+	rs := (t1 - t2) / (t1 + t2)
+	t3 := cos2*a2b2 + sin2*sin2
+	t4 := t2 * sin2
+	rp := rs * (t3 - t4) / (t3 + t4)
+	return (rs + rp) / 2
+}
+
+// ThinFilm is the Fresnel reflectance of Base beneath a transparent film Thickness nanometers thick
+// with the refractive index IOR, such as oil on water or a soap bubble.
+// Light reflected from the top and bottom of the film interferes, brightening some wavelengths and canceling others.
+// Each channel is a single wavelength, so films much thicker than a micron alias instead of fading to white.
+// A Base with zero K is a dielectric.
+// https://en.wikipedia.org/wiki/Thin-film_interference
+type ThinFilm struct {
+	Thickness float64
+	IOR       float64
+	Base      Conductor
+}
+
+// wavelengths are the red, green, and blue wavelengths of light, in nanometers.
+var wavelengths = [3]float64{650, 550, 450}
+
+func (t ThinFilm) Reflectance(cos float64) rgb.Energy {
+	return rgb.Energy{
+		X: t.airy(cos, wavelengths[0], complex(t.Base.Eta.X, t.Base.K.X)),
+		Y: t.airy(cos, wavelengths[1], complex(t.Base.Eta.Y, t.Base.K.Y)),
+		Z: t.airy(cos, wavelengths[2], complex(t.Base.Eta.Z, t.Base.K.Z)),
+	}
+}
+
+// airy sums the infinite series of reflections within the film at wavelength, above a base with index n3,
+// averaging the s and p polarizations.
+// https://en.wikipedia.org/wiki/Transfer-matrix_method_(optics)
+func (t ThinFilm) airy(cos, wavelength float64, n3 complex128) float64 {
+	cos = math.Max(0, math.Min(1, cos))
+	n1, n2 := complex(1, 0), complex(t.IOR, 0)
+	sin2 := complex(1-cos*cos, 0)
+	c1 := complex(cos, 0)
+	c2 := cmplx.Sqrt(1 - sin2/(n2*n2))
+	c3 := cmplx.Sqrt(1 - sin2/(n3*n3))
+	phase := cmplx.Exp(complex(0, 4*math.Pi*t.Thickness/wavelength) * n2 * c2)
+	sum := func(r12, r23 complex128) float64 {
+		r := (r12 + r23*phase) / (1 + r12*r23*phase)
+		return real(r * cmplx.Conj(r))
+	}
+	rs := sum(amplitudeS(n1, c1, n2, c2), amplitudeS(n2, c2, n3, c3))
+	rp := sum(amplitudeP(n1, c1, n2, c2), amplitudeP(n2, c2, n3, c3))
+	return math.Min(1, (rs+rp)/2)
+}
+
+// amplitudeS returns the Fresnel amplitude of s-polarized light reflected from index n1 to n2,
+// with cosines c1 and c2 of the angles of incidence and refraction.
+func amplitudeS(n1, c1, n2, c2 complex128) complex128 {
+	return (n1*c1 - n2*c2) / (n1*c1 + n2*c2)
+}
+
+// amplitudeP returns the Fresnel amplitude of p-polarized light reflected from index n1 to n2.
+func amplitudeP(n1, c1, n2, c2 complex128) complex128 {
+	return (n2*c1 - n1*c2) / (n2*c1 + n1*c2)
+}
@@ -7,8 +7,13 @@ import (
 	"github.com/hunterloftis/pbr/pkg/rgb"
 )
 
+// Ignore passes light straight through, as if there were no surface at all.
 type Ignore struct{}
 
+func (i Ignore) Invisible() bool {
+	return true
+}
+
 func (i Ignore) Sample(wo geom.Dir, rnd *rand.Rand) (geom.Dir, float64, bool) {
 	return wo.Inv(), 1, false
 }
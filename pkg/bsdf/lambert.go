@@ -24,5 +24,8 @@ func (l Lambert) PDF(wi, wo geom.Dir) float64 {
 
 func (l Lambert) Eval(wi, wo geom.Dir) rgb.Energy {
 	cos := wi.Dot(geom.Up)
+	if cos <= 0 {
+		return rgb.Black // beneath the surface, such as a light sampled from behind a sheet
+	}
 	return l.Color.Scaled(cos * l.Multiplier)
 }
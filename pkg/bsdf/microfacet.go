@@ -5,61 +5,76 @@ import (
 	"math/rand"
 
 	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/render"
 	"github.com/hunterloftis/pbr/pkg/rgb"
 )
 
-// TODO: fix issue where Roughness == 0 causes bad render
-
-// Cook-Torrance microfacet model
+// Cook-Torrance microfacet model, with an anisotropic GGX distribution of microfacet normals.
+// Roughness is the GGX alpha, and should be at least Smooth; Mirror replaces it on smoother surfaces.
+// Anisotropic, from 0 to 1, stretches the highlight along the direction Rotation.
+// A Kulla-Conty lobe restores the energy that rough microfacets would reflect between one another.
+// http://graphicrants.blogspot.com/2013/08/specular-brdf-reference.html
 type Microfacet struct {
-	Specular   rgb.Energy
-	Roughness  float64
-	Multiplier float64
+	Specular    rgb.Energy
+	Roughness   float64
+	Anisotropic float64
+	Rotation    float64 // of the anisotropic highlight, in radians from the tangent space X axis toward Z
+	Multiplier  float64
+	Fresnel     Fresnel // replaces Schlick's approximation with Specular, if present
 }
 
-// https://schuttejoe.github.io/post/ggximportancesamplingpart1/
-// https://agraphicsguy.wordpress.com/2015/11/01/sampling-microfacet-brdf/
 func (m Microfacet) Sample(wo geom.Dir, rnd *rand.Rand) (geom.Dir, float64, bool) {
-	r0 := rnd.Float64()
-	r1 := rnd.Float64()
-	a := m.Roughness
-	a2 := a * a
-	theta := math.Acos(math.Sqrt((1 - r0) / ((a2-1)*r0 + 1)))
-	phi := 2 * math.Pi * r1
-	wm, _ := geom.SphericalDirection(theta, phi)
-	wi := wo.Reflect2(wm)
-	return wi, m.PDF(wi, wo), wo.Dot(geom.Up) > 0
+	if wo.Y <= 0 {
+		return geom.Up, 1, false
+	}
+	var wi geom.Dir
+	if rnd.Float64() < m.lost(wo) {
+		wi, _ = geom.Up.RandHemiCos(rnd)
+	} else {
+		wm := unaligned(m.distribution().sample(aligned(wo, m.Rotation), rnd), m.Rotation)
+		wi = wo.Reflect2(wm)
+	}
+	if wi.Y <= 0 {
+		return wi, 1, false // reflected beneath the surface, with no contribution
+	}
+	return wi, m.PDF(wi, wo), true
 }
 
-// https://schuttejoe.github.io/post/ggximportancesamplingpart1/
-// https://agraphicsguy.wordpress.com/2015/11/01/sampling-microfacet-brdf/
-// https://en.wikipedia.org/wiki/List_of_common_coordinate_transformations#From_Cartesian_coordinates_2
 func (m Microfacet) PDF(wi, wo geom.Dir) float64 {
-	wg := geom.Up
 	wm := wo.Half(wi)
-	a := m.Roughness
-	a2 := a * a
-	cosTheta := wg.Dot(wm)
-	exp := (a2-1)*cosTheta*cosTheta + 1
-	D := a2 / (math.Pi * exp * exp)
-	return (D * wm.Dot(wg)) / (4 * wo.Dot(wm))
+	cos := wo.Dot(wm)
+	if cos <= 0 || wi.Y <= 0 {
+		return 1
+	}
+	single := m.distribution().pdf(aligned(wo, m.Rotation), aligned(wm, m.Rotation)) / (4 * cos)
+	return lerp(m.lost(wo), single, wi.Y/math.Pi)
 }
 
-// http://graphicrants.blogspot.com/2013/08/specular-brdf-reference.html
 func (m Microfacet) Eval(wi, wo geom.Dir) rgb.Energy {
-	wg := geom.Up
-	wm := wo.Half(wi)
-	if wi.Y <= 0 || wi.Dot(wm) <= 0 {
-		return rgb.White // exiting, shouldn't be here
+	if wi.Y <= 0 || wo.Y <= 0 {
+		return rgb.Black
 	}
-	F := rgb.Energy{
-		X: fresnelSchlick(wi.Dot(wm), m.Specular.X),
-		Y: fresnelSchlick(wi.Dot(wm), m.Specular.Y),
-		Z: fresnelSchlick(wi.Dot(wm), m.Specular.Z),
-	}
-	D := ggx(wi, wo, wg, m.Roughness)  // The NDF (Normal Distribution Function)
-	G := smithGGX(wo, wg, m.Roughness) // The Geometric Shadowing function
-	r := (D * G) / (4 * wg.Dot(wi) * wg.Dot(wo))
-	cos := wi.Dot(wm)
-	return F.Scaled(r * cos * m.Multiplier)
+	wm := wo.Half(wi)
+	d := m.distribution()
+	ao, ai, am := aligned(wo, m.Rotation), aligned(wi, m.Rotation), aligned(wm, m.Rotation)
+	f := fresnel(m.Fresnel, m.Specular)
+	r := d.d(am) * d.g(ao, ai) / (4 * wi.Y * wo.Y)
+	single := f.Reflectance(wi.Dot(wm)).Scaled(r)
+	return single.Plus(multiscatter(wi, wo, d.alpha(), f)).Scaled(wi.Y * m.Multiplier)
+}
+
+// Oriented returns m with its anisotropic highlight rotated by angle.
+func (m Microfacet) Oriented(angle float64) render.BSDF {
+	m.Rotation += angle
+	return m
+}
+
+// lost returns the fraction of energy toward wo that single-scattering microfacets don't reflect,
+// which is also the probability of sampling the Kulla-Conty lobe.
+func (m Microfacet) lost(wo geom.Dir) float64 {
+	return 1 - albedo.at(m.distribution().alpha(), wo.Y)
+}
+
+func (m Microfacet) distribution() distribution {
+	return anisotropic(m.Roughness, m.Anisotropic)
 }
@@ -0,0 +1,99 @@
+package bsdf
+
+import (
+	"math"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/rgb"
+)
+
+// albedoSize is the number of cosines and alphas at which the albedo table is sampled.
+const albedoSize = 32
+
+// albedo holds the directional albedo of single-scattering GGX reflection with a Fresnel reflectance of 1,
+// which falls short of 1 by the energy that would scatter between microfacets more than once.
+// https://blog.selfshadow.com/publications/s2017-shading-course/imageworks/s2017_pbs_imageworks_slides_v2.pdf
+var albedo = newAlbedoTable(16)
+
+type albedoTable struct {
+	e   [albedoSize][albedoSize]float64 // by alpha, then by cosine of the view angle
+	avg [albedoSize]float64             // the cosine-weighted average of e over the hemisphere, by alpha
+}
+
+// newAlbedoTable estimates each directional albedo from a strata by strata grid of samples of the visible normals.
+func newAlbedoTable(strata int) *albedoTable {
+	t := &albedoTable{}
+	for i := range t.e {
+		a := math.Max(minAlpha, float64(i)/(albedoSize-1))
+		d := distribution{ax: a, az: a}
+		for j := range t.e[i] {
+			cos := math.Max(0.001, float64(j)/(albedoSize-1))
+			wo := geom.Dir{math.Sqrt(1 - cos*cos), cos, 0}
+			sum := 0.0
+			for k := 0; k < strata*strata; k++ {
+				u1, u2 := (float64(k/strata)+0.5)/float64(strata), (float64(k%strata)+0.5)/float64(strata)
+				if wi := wo.Reflect2(d.sampleAt(wo, u1, u2)); wi.Y > 0 {
+					sum += d.g(wo, wi) / d.g1(wo)
+				}
+			}
+			t.e[i][j] = sum / float64(strata*strata)
+		}
+		for j := 1; j < albedoSize; j++ {
+			mu0, mu1 := float64(j-1)/(albedoSize-1), float64(j)/(albedoSize-1)
+			t.avg[i] += (t.e[i][j-1]*mu0 + t.e[i][j]*mu1) * (mu1 - mu0) // 2 * the trapezoid rule
+		}
+	}
+	return t
+}
+
+// at returns the directional albedo toward a direction with cosine cos, interpolated between samples.
+func (t *albedoTable) at(alpha, cos float64) float64 {
+	i, fi := index(alpha)
+	j, fj := index(cos)
+	e0 := lerp(fj, t.e[i][j], t.e[i][j+1])
+	e1 := lerp(fj, t.e[i+1][j], t.e[i+1][j+1])
+	return lerp(fi, e0, e1)
+}
+
+// average returns the cosine-weighted average directional albedo over the hemisphere.
+func (t *albedoTable) average(alpha float64) float64 {
+	i, fi := index(alpha)
+	return lerp(fi, t.avg[i], t.avg[i+1])
+}
+
+// index returns the sample below x, from 0 to 1, and x's fractional distance to the next sample.
+func index(x float64) (int, float64) {
+	x = math.Max(0, math.Min(1, x)) * (albedoSize - 1)
+	i := int(math.Min(x, albedoSize-2))
+	return i, x - float64(i)
+}
+
+func lerp(t, a, b float64) float64 {
+	return a + (b-a)*t
+}
+
+// averageFresnel returns the cosine-weighted average of f over the hemisphere.
+func averageFresnel(f Fresnel) rgb.Energy {
+	const steps = 16
+	var sum rgb.Energy
+	for i := 0; i < steps; i++ {
+		cos := (float64(i) + 0.5) / steps
+		sum = sum.Plus(f.Reflectance(cos).Scaled(2 * cos / steps))
+	}
+	return sum
+}
+
+// multiscatter returns the Kulla-Conty lobe, a diffuse-like reflection of the energy
+// that single-scattering microfacets of alpha lose between wo and wi, tinted by the Fresnel reflectance f.
+func multiscatter(wi, wo geom.Dir, alpha float64, f Fresnel) rgb.Energy {
+	eavg := albedo.average(alpha)
+	if eavg >= 1 {
+		return rgb.Black
+	}
+	favg := averageFresnel(f)
+	tint := func(favg float64) float64 {
+		return favg * favg * eavg / (1 - favg*(1-eavg))
+	}
+	k := (1 - albedo.at(alpha, wo.Y)) * (1 - albedo.at(alpha, wi.Y)) / (math.Pi * (1 - eavg))
+	return rgb.Energy{tint(favg.X), tint(favg.Y), tint(favg.Z)}.Scaled(k)
+}
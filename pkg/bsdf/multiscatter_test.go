@@ -0,0 +1,30 @@
+package bsdf
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/rgb"
+)
+
+// TestWhiteFurnace checks that a perfectly reflective Microfacet reflects all of the energy it receives.
+func TestWhiteFurnace(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	for _, roughness := range []float64{0.1, 0.5, 1} {
+		m := Microfacet{Specular: rgb.White, Roughness: roughness, Multiplier: 1}
+		for _, cos := range []float64{0.2, 0.5, 1} {
+			wo := geom.Dir{math.Sqrt(1 - cos*cos), cos, 0}
+			const samples = 50000
+			sum := 0.0
+			for i := 0; i < samples; i++ {
+				wi, pdf, _ := m.Sample(wo, rnd)
+				sum += m.Eval(wi, wo).Y / pdf
+			}
+			if e := sum / samples; math.Abs(e-1) > 0.02 {
+				t.Errorf("Expected 1 at roughness %v, cos %v, got %v", roughness, cos, e)
+			}
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package bsdf
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/render"
+	"github.com/hunterloftis/pbr/pkg/rgb"
+)
+
+// Principled is a layered model after Disney's principled BRDF:
+// a diffuse base with sheen, an anisotropic GGX specular layer, a clearcoat, and specular transmission.
+// Transmission refracts through the same GGX microfacets as Transmit, and is a delta lobe below Smooth.
+// Each sample picks a lobe in proportion to its reflectance toward wo,
+// so Sample's pdf is that of the whole mixture.
+// https://media.disneyanimation.com/uploads/production/publication_asset/48/asset/s2012_pbs_disney_brdf_notes_v3.pdf
+// https://docs.blender.org/manual/en/latest/render/shader_nodes/shader/principled.html
+type Principled struct {
+	Color              rgb.Energy
+	Metallic           float64
+	Roughness          float64
+	Specular           float64 // 0.5 reflects 4% at normal incidence
+	SpecularTint       float64
+	Anisotropic        float64
+	Rotation           float64 // of the anisotropic highlight, in radians from the tangent space X axis toward Z
+	Sheen              float64
+	SheenTint          float64
+	Clearcoat          float64
+	ClearcoatRoughness float64
+	Transmission       float64
+	IOR                float64
+	Exterior           float64 // refractive index beyond the surface, or zero for a vacuum, as in Transmit
+}
+
+// lobes holds the reflectance of each of a Principled BSDF's lobes toward wo.
+type lobes struct {
+	diffuse, specular, clearcoat, transmission float64
+}
+
+func (l lobes) total() float64 {
+	return l.diffuse + l.specular + l.clearcoat + l.transmission
+}
+
+func (p Principled) Sample(wo geom.Dir, rnd *rand.Rand) (geom.Dir, float64, bool) {
+	l := p.lobes(wo)
+	total := l.total()
+	if wo.Y <= 0 || total == 0 {
+		return geom.Up, 1, false
+	}
+	var wi geom.Dir
+	switch r := rnd.Float64() * total; {
+	case r < l.diffuse:
+		wi, _ = geom.Up.RandHemiCos(rnd)
+	case r < l.diffuse+l.specular:
+		wm := unaligned(newDistribution(p.Roughness, p.Anisotropic).sample(aligned(wo, p.Rotation), rnd), p.Rotation)
+		wi = wo.Reflect2(wm)
+	case r < l.diffuse+l.specular+l.clearcoat:
+		a2 := p.clearcoatAlpha() * p.clearcoatAlpha()
+		cos := math.Sqrt((1 - math.Pow(a2, 1-rnd.Float64())) / (1 - a2))
+		wm, _ := geom.SphericalDirection(math.Acos(cos), 2*math.Pi*rnd.Float64())
+		wi = wo.Reflect2(wm)
+	default:
+		if p.smooth() {
+			return refract(wo.Inv(), geom.Up, p.eta()), l.transmission / total, false
+		}
+		wi, pdf, _ := p.transmitter().Sample(wo, rnd)
+		if math.IsInf(pdf, 0) {
+			return wi, 1, false // scattered to the wrong side, with no contribution
+		}
+		return wi, p.PDF(wi, wo), true
+	}
+	if wi.Y <= 0 {
+		return wi, 1, false // reflected beneath the surface, with no contribution
+	}
+	return wi, p.PDF(wi, wo), true
+}
+
+func (p Principled) PDF(wi, wo geom.Dir) float64 {
+	l := p.lobes(wo)
+	total := l.total()
+	if wo.Y <= 0 || total == 0 {
+		return 1
+	}
+	if p.smooth() && wi.Y <= 0 {
+		return l.transmission / total
+	}
+	pdf := 0.0
+	if !p.smooth() {
+		pdf = l.transmission * p.transmitter().PDF(wi, wo)
+	}
+	if wi.Y <= 0 {
+		return pdf / total
+	}
+	wm := wo.Half(wi)
+	specular := newDistribution(p.Roughness, p.Anisotropic).pdf(aligned(wo, p.Rotation), aligned(wm, p.Rotation)) / (4 * wo.Dot(wm))
+	clearcoat := gtr1(wm.Y, p.clearcoatAlpha()) * wm.Y / (4 * wo.Dot(wm))
+	pdf += l.diffuse*wi.Y/math.Pi + l.specular*specular + l.clearcoat*clearcoat
+	return pdf / total
+}
+
+// Eval returns the reflectance of each lobe, times the cosine of wi.
+// Below Smooth, transmission is a delta lobe, so it is only present in the exact direction of refraction.
+func (p Principled) Eval(wi, wo geom.Dir) rgb.Energy {
+	if wo.Y <= 0 {
+		return rgb.Black
+	}
+	dielectric := 1 - p.Metallic
+	if wi.Y <= 0 {
+		if p.Transmission == 0 {
+			return rgb.Black
+		}
+		if !p.smooth() {
+			return p.transmitter().Eval(wi, wo).Times(p.Color).Scaled(dielectric * p.Transmission)
+		}
+		if !wi.Equals(refract(wo.Inv(), geom.Up, p.eta())) {
+			return rgb.Black
+		}
+		f := fresnelSchlick(wo.Y, p.f0())
+		return p.Color.Scaled(dielectric * p.Transmission * (1 - f))
+	}
+	wm := wo.Half(wi)
+	fh := schlickWeight(wi.Dot(wm))
+	tint := p.tint()
+
+	// https://github.com/wdas/brdf/blob/main/src/brdfs/disney.brdf
+	fl, fv := schlickWeight(wi.Y), schlickWeight(wo.Y)
+	fd90 := 0.5 + 2*p.Roughness*wi.Dot(wm)*wi.Dot(wm)
+	diffuse := p.Color.Scaled((1 + (fd90-1)*fl) * (1 + (fd90-1)*fv) / math.Pi)
+	sheen := rgb.White.Lerp(tint, p.SheenTint).Scaled(p.Sheen * fh)
+	base := diffuse.Plus(sheen).Scaled(dielectric * (1 - p.Transmission))
+
+	d := newDistribution(p.Roughness, p.Anisotropic)
+	ao, ai, am := aligned(wo, p.Rotation), aligned(wi, p.Rotation), aligned(wm, p.Rotation)
+	f := p.specular0().Lerp(rgb.White, fh)
+	specular := f.Scaled(d.d(am) * d.g(ao, ai) / (4 * wi.Y * wo.Y))
+
+	cc := newDistribution(0.5, 0) // the clearcoat's fixed masking roughness (0.25 alpha)
+	fc := 0.04 + 0.96*fh
+	clearcoat := 0.25 * p.Clearcoat * gtr1(wm.Y, p.clearcoatAlpha()) * fc * cc.g1(wi) * cc.g1(wo) / (4 * wi.Y * wo.Y)
+
+	return base.Plus(specular).Plus(rgb.Energy{clearcoat, clearcoat, clearcoat}).Scaled(wi.Y)
+}
+
+// Oriented returns p with its anisotropic highlight rotated by angle.
+func (p Principled) Oriented(angle float64) render.BSDF {
+	p.Rotation += angle
+	return p
+}
+
+// lobes estimates the reflectance of each lobe toward wo.
+// Sheen reflects much less than its weight, so it only nudges the diffuse lobe.
+func (p Principled) lobes(wo geom.Dir) lobes {
+	fv := schlickWeight(math.Max(0, wo.Y))
+	dielectric := 1 - p.Metallic
+	return lobes{
+		diffuse:      (luminance(p.Color) + 0.1*p.Sheen) * dielectric * (1 - p.Transmission),
+		specular:     luminance(p.specular0().Lerp(rgb.White, fv)),
+		clearcoat:    0.25 * p.Clearcoat * (0.04 + 0.96*fv),
+		transmission: luminance(p.Color) * dielectric * p.Transmission * (1 - fresnelSchlick(wo.Y, p.f0())),
+	}
+}
+
+// specular0 returns the specular color at normal incidence.
+func (p Principled) specular0() rgb.Energy {
+	dielectric := rgb.White.Lerp(p.tint(), p.SpecularTint).Scaled(p.Specular * 0.08)
+	return dielectric.Lerp(p.Color, p.Metallic)
+}
+
+// tint returns the hue and saturation of Color at a luminance of 1.
+func (p Principled) tint() rgb.Energy {
+	lum := luminance(p.Color)
+	if lum <= 0 {
+		return rgb.White
+	}
+	return p.Color.Scaled(1 / lum)
+}
+
+// f0 returns the reflectance at normal incidence of a dielectric with the index of refraction IOR within Exterior.
+func (p Principled) f0() float64 {
+	eta := p.eta()
+	return math.Pow((eta-1)/(eta+1), 2)
+}
+
+// smooth returns whether the transmission lobe is too smooth for microfacets, and refracts in a single direction instead.
+func (p Principled) smooth() bool {
+	return p.Roughness*p.Roughness < Smooth
+}
+
+// transmitter returns the rough dielectric through which light refracts, with the alpha of the specular lobe.
+func (p Principled) transmitter() Transmit {
+	ior := vacuum(p.IOR)
+	return Transmit{
+		Specular:   math.Pow((ior-1)/(ior+1), 2),
+		Roughness:  p.Roughness * p.Roughness,
+		Multiplier: 1,
+		Exterior:   p.Exterior,
+	}
+}
+
+// eta returns the ratio of the refractive index inside the surface to that beyond it.
+func (p Principled) eta() float64 {
+	return vacuum(p.IOR) / vacuum(p.Exterior)
+}
+
+func (p Principled) interior() float64 {
+	return vacuum(p.IOR)
+}
+
+// within returns p with a refractive index of exterior beyond it.
+// Unlike a bare dielectric, it still scatters light where the indices match.
+func (p Principled) within(exterior float64) render.BSDF {
+	p.Exterior = exterior
+	return p
+}
+
+func (p Principled) clearcoatAlpha() float64 {
+	return math.Max(minAlpha, math.Min(0.999, p.ClearcoatRoughness*p.ClearcoatRoughness))
+}
+
+// gtr1 is the Generalized Trowbridge-Reitz distribution with an exponent of 1,
+// used for the clearcoat's long-tailed highlight.
+func gtr1(cos, alpha float64) float64 {
+	if alpha >= 1 {
+		return 1 / math.Pi
+	}
+	a2 := alpha * alpha
+	t := 1 + (a2-1)*cos*cos
+	return (a2 - 1) / (math.Pi * math.Log(a2) * t)
+}
+
+func schlickWeight(cos float64) float64 {
+	return math.Pow(math.Max(0, math.Min(1, 1-cos)), 5)
+}
+
+// https://en.wikipedia.org/wiki/Relative_luminance
+func luminance(e rgb.Energy) float64 {
+	return 0.2126*e.X + 0.7152*e.Y + 0.0722*e.Z
+}
@@ -0,0 +1,153 @@
+package bsdf
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/rgb"
+)
+
+// ThinGlass is a perfectly smooth dielectric sheet, like a window pane or a soap bubble,
+// so thin that light passes straight through it without refracting.
+// Its reflectance includes the light that bounces between its two faces.
+// https://pbr-book.org/4ed/Reflection_Models/Dielectric_BSDF#ThinDielectricBSDF
+type ThinGlass struct {
+	Specular   float64
+	Multiplier float64
+}
+
+func (g ThinGlass) Sample(wo geom.Dir, rnd *rand.Rand) (geom.Dir, float64, bool) {
+	r := sheet(math.Abs(wo.Y), g.Specular)
+	if rnd.Float64() < r {
+		return mirror(wo), r, false
+	}
+	return wo.Inv(), 1 - r, false
+}
+
+// PDF returns the probability of sampling wi, which is zero outside of the directions of reflection and transmission.
+func (g ThinGlass) PDF(wi, wo geom.Dir) float64 {
+	r := sheet(math.Abs(wo.Y), g.Specular)
+	if wi.Equals(mirror(wo)) {
+		return r
+	}
+	if wi.Equals(wo.Inv()) {
+		return 1 - r
+	}
+	return 0
+}
+
+func (g ThinGlass) Eval(wi, wo geom.Dir) rgb.Energy {
+	return rgb.White.Scaled(g.PDF(wi, wo) * g.Multiplier)
+}
+
+func (g ThinGlass) Delta() bool {
+	return true
+}
+
+// ThinTransmit is a rough dielectric sheet, like frosted plastic film, that reflects light from a GGX distribution
+// of microfacets and transmits the rest through the mirror image of that lobe beneath it,
+// which is centered on the straight-through direction.
+// Roughness is the GGX alpha, as in Microfacet.
+// Since the sheet absorbs nothing, the light that single scattering loses between microfacets
+// is restored by dividing by their directional albedo.
+type ThinTransmit struct {
+	Specular   float64
+	Roughness  float64
+	Multiplier float64
+}
+
+func (t ThinTransmit) Sample(wo geom.Dir, rnd *rand.Rand) (geom.Dir, float64, bool) {
+	lo, flip := facing(wo)
+	wm := t.distribution().sample(lo, rnd)
+	li := lo.Reflect2(wm)
+	if li.Y <= 0 {
+		return flip(li), math.Inf(1), false // masked by another microfacet, with no contribution
+	}
+	if rnd.Float64() >= sheet(lo.Dot(wm), t.Specular) {
+		li = geom.Dir{li.X, -li.Y, li.Z}
+	}
+	wi := flip(li)
+	return wi, t.PDF(wi, wo), true
+}
+
+func (t ThinTransmit) PDF(wi, wo geom.Dir) float64 {
+	lo, li, r, ok := t.reflected(wi, wo)
+	if !ok {
+		return 0
+	}
+	wm, _ := geom.Vec(lo).Plus(geom.Vec(li)).Unit()
+	return r * t.distribution().pdf(lo, wm) / (4 * lo.Dot(wm))
+}
+
+func (t ThinTransmit) Eval(wi, wo geom.Dir) rgb.Energy {
+	lo, li, r, ok := t.reflected(wi, wo)
+	if !ok {
+		return rgb.Black
+	}
+	wm, _ := geom.Vec(lo).Plus(geom.Vec(li)).Unit()
+	d := t.distribution()
+	e := albedo.at(d.alpha(), lo.Y)
+	return rgb.White.Scaled(r * d.d(wm) * d.g(lo, li) / (4 * lo.Y * e) * t.Multiplier)
+}
+
+func (t ThinTransmit) distribution() distribution {
+	return anisotropic(t.Roughness, 0)
+}
+
+// reflected mirrors wo, and wi if it is transmitted, above the sheet.
+// It returns the mirrored directions and the fraction of light the sheet scatters to the side of wi,
+// or false if no microfacet scatters wo into wi.
+func (t ThinTransmit) reflected(wi, wo geom.Dir) (lo, li geom.Dir, r float64, ok bool) {
+	lo, flip := facing(wo)
+	li = flip(wi)
+	transmitted := li.Y < 0
+	if transmitted {
+		li = geom.Dir{li.X, -li.Y, li.Z}
+	}
+	wm, ok := geom.Vec(lo).Plus(geom.Vec(li)).Unit()
+	if !ok || lo.Y == 0 || li.Y == 0 || lo.Dot(wm) <= 0 {
+		return lo, li, 0, false
+	}
+	r = sheet(lo.Dot(wm), t.Specular)
+	if transmitted {
+		r = 1 - r
+	}
+	return lo, li, r, true
+}
+
+// Translucent scatters light diffusely through a thin surface to the side opposite wo, like paper or a leaf.
+// It mirrors Lambert beneath the surface.
+type Translucent struct {
+	Color      rgb.Energy
+	Multiplier float64
+}
+
+func (t Translucent) Sample(wo geom.Dir, rnd *rand.Rand) (geom.Dir, float64, bool) {
+	wi, _ := geom.Up.RandHemiCos(rnd)
+	if wo.Y > 0 {
+		wi = geom.Dir{wi.X, -wi.Y, wi.Z}
+	}
+	return wi, t.PDF(wi, wo), true
+}
+
+func (t Translucent) PDF(wi, wo geom.Dir) float64 {
+	return math.Abs(wi.Y) * math.Pi
+}
+
+func (t Translucent) Eval(wi, wo geom.Dir) rgb.Energy {
+	if (wi.Y > 0) == (wo.Y > 0) {
+		return rgb.Black
+	}
+	return t.Color.Scaled(math.Abs(wi.Y) * t.Multiplier)
+}
+
+// sheet returns the fraction of light reflected by a thin dielectric sheet at cos to the normal,
+// from the Fresnel reflectance of each face and all of the light reflected between them.
+func sheet(cos, specular float64) float64 {
+	r := fresnelDielectric(cos, 1/fresnelToRefractiveIndex(specular))
+	if r >= 1 {
+		return 1
+	}
+	return 2 * r / (1 + r)
+}
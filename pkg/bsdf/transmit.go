@@ -5,32 +5,163 @@ import (
 	"math/rand"
 
 	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/render"
 	"github.com/hunterloftis/pbr/pkg/rgb"
 )
 
-// Simple, perfect refraction with no roughness
+// Transmit is a rough dielectric boundary that reflects or refracts light, in proportion to its Fresnel reflectance,
+// from a GGX distribution of microfacets.
+// Roughness is the GGX alpha, as in Microfacet, so the reflections and refractions of a material match.
+// wo may be on either side of the boundary; the outside is toward the normal.
+// Radiance isn't rescaled by the squared ratio of refractive indices,
+// which cancels out along paths that enter and leave a closed object.
+// https://www.cs.cornell.edu/~srm/publications/EGSR07-btdf.pdf
 type Transmit struct {
 	Specular   float64
 	Roughness  float64
 	Multiplier float64
+	Exterior   float64 // refractive index beyond the boundary, or zero for a vacuum
 }
 
 func (t Transmit) Sample(wo geom.Dir, rnd *rand.Rand) (geom.Dir, float64, bool) {
-	ior := fresnelToRefractiveIndex(t.Specular)
-	return refract(wo.Inv(), geom.Up, ior), 1, false
+	lo, flip := facing(wo)
+	eta := t.eta(wo)
+	wm := t.distribution().sample(lo, rnd)
+	li, ok := refracted(lo, wm, eta)
+	reflect := !ok || rnd.Float64() < fresnelDielectric(lo.Dot(wm), eta)
+	if reflect {
+		li = lo.Reflect2(wm)
+	}
+	wi := flip(li)
+	if (li.Y > 0) != reflect {
+		return wi, math.Inf(1), false // scattered to the wrong side, with no contribution
+	}
+	return wi, t.PDF(wi, wo), wo.Y > 0
 }
 
 func (t Transmit) PDF(wi, wo geom.Dir) float64 {
-	return 1
+	lo, flip := facing(wo)
+	li := flip(wi)
+	eta := t.eta(wo)
+	wm, reflect, ok := halfway(lo, li, eta)
+	if !ok {
+		return 0
+	}
+	d := t.distribution()
+	f := fresnelDielectric(lo.Dot(wm), eta)
+	if reflect {
+		return f * d.pdf(lo, wm) / (4 * lo.Dot(wm))
+	}
+	denom := lo.Dot(wm)*eta + li.Dot(wm)
+	return (1 - f) * d.pdf(lo, wm) * math.Abs(li.Dot(wm)) / (denom * denom)
 }
 
 func (t Transmit) Eval(wi, wo geom.Dir) rgb.Energy {
-	ior := fresnelToRefractiveIndex(t.Specular)
-	dir := refract(wo.Inv(), geom.Up, ior)
-	if !wi.Equals(dir) {
+	lo, flip := facing(wo)
+	li := flip(wi)
+	eta := t.eta(wo)
+	wm, reflect, ok := halfway(lo, li, eta)
+	if !ok {
 		return rgb.Black
 	}
-	return rgb.White.Scaled(t.Multiplier)
+	d := t.distribution()
+	f := fresnelDielectric(lo.Dot(wm), eta)
+	dg := d.d(wm) * d.g(lo, li)
+	if reflect {
+		return rgb.White.Scaled(f * dg / (4 * lo.Y) * t.Multiplier)
+	}
+	denom := lo.Dot(wm)*eta + li.Dot(wm)
+	r := (1 - f) * dg * math.Abs(li.Dot(wm)*lo.Dot(wm)) / (lo.Y * denom * denom)
+	return rgb.White.Scaled(r * t.Multiplier)
+}
+
+func (t Transmit) distribution() distribution {
+	return anisotropic(t.Roughness, 0)
+}
+
+// IOR returns the refractive index inside the boundary.
+func (t Transmit) IOR() float64 {
+	return fresnelToRefractiveIndex(t.Specular)
+}
+
+func (t Transmit) interior() float64 {
+	return t.IOR()
+}
+
+func (t Transmit) within(exterior float64) render.BSDF {
+	if matched(t.IOR(), exterior) {
+		return Ignore{}
+	}
+	t.Exterior = exterior
+	return t
+}
+
+// eta returns the ratio of the refractive index on the side of wo to that on the other side.
+func (t Transmit) eta(wo geom.Dir) float64 {
+	ior := t.IOR() / vacuum(t.Exterior)
+	if wo.Y < 0 {
+		return ior
+	}
+	return 1 / ior
+}
+
+// facing mirrors wo, if necessary, to lie above the surface.
+// It returns the mirrored direction and a function that mirrors other directions the same way.
+func facing(wo geom.Dir) (geom.Dir, func(geom.Dir) geom.Dir) {
+	if wo.Y >= 0 {
+		return wo, func(w geom.Dir) geom.Dir { return w }
+	}
+	mirror := func(w geom.Dir) geom.Dir { return geom.Dir{w.X, -w.Y, w.Z} }
+	return mirror(wo), mirror
+}
+
+// halfway returns the microfacet normal that scatters wo (above the surface) into wi,
+// with the ratio of refractive indices eta, and whether the scattering is a reflection.
+func halfway(wo, wi geom.Dir, eta float64) (wm geom.Dir, reflect, ok bool) {
+	reflect = wi.Y > 0
+	h := geom.Vec(wo).Scaled(eta).Plus(geom.Vec(wi))
+	if reflect {
+		h = geom.Vec(wo).Plus(geom.Vec(wi))
+	}
+	wm, ok = h.Unit()
+	if !ok || wi.Y == 0 {
+		return wm, reflect, false
+	}
+	if wm.Y < 0 {
+		wm = wm.Inv()
+	}
+	if wo.Dot(wm) <= 0 || (wi.Dot(wm) > 0) != reflect {
+		return wm, reflect, false
+	}
+	return wm, reflect, true
+}
+
+// refracted returns the refraction of wo through the microfacet normal wm on the same side,
+// with the ratio of refractive indices eta, or false on total internal reflection.
+// https://www.pbr-book.org/3ed-2018/Reflection_Models/Specular_Reflection_and_Transmission#SpecularTransmission
+func refracted(wo, wm geom.Dir, eta float64) (geom.Dir, bool) {
+	cosI := wo.Dot(wm)
+	sin2T := eta * eta * math.Max(0, 1-cosI*cosI)
+	if sin2T >= 1 {
+		return wo, false
+	}
+	cosT := math.Sqrt(1 - sin2T)
+	wi, ok := wo.Scaled(-eta).Plus(wm.Scaled(eta*cosI - cosT)).Unit()
+	return wi, ok
+}
+
+// fresnelDielectric returns the fraction of unpolarized light reflected at a dielectric boundary,
+// for a ray at cosI to the normal and a ratio of refractive indices eta.
+// https://en.wikipedia.org/wiki/Fresnel_equations
+func fresnelDielectric(cosI, eta float64) float64 {
+	sin2T := eta * eta * math.Max(0, 1-cosI*cosI)
+	if sin2T >= 1 {
+		return 1
+	}
+	cosT := math.Sqrt(1 - sin2T)
+	rs := (eta*cosI - cosT) / (eta*cosI + cosT)
+	rp := (cosI - eta*cosT) / (cosI + eta*cosT)
+	return (rs*rs + rp*rp) / 2
 }
 
 // https://www.scratchapixel.com/lessons/3d-basic-rendering/introduction-to-shading/reflection-refraction-fresnel
@@ -0,0 +1,75 @@
+package bsdf
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/render"
+	"github.com/hunterloftis/pbr/pkg/rgb"
+)
+
+// lobeSamples is the number of directions sampled from each lobe.
+const lobeSamples = 50000
+
+// TestLobeWidths checks that rough dielectrics reflect and refract from microfacets as rough as Microfacet's,
+// by comparing the lower quartile of the slopes of the microfacets that scatter light straight down onto each lobe.
+// Quartiles ignore the steepest microfacets, which reflect beneath the surface but can still refract.
+func TestLobeWidths(t *testing.T) {
+	const roughness = 0.2
+	rnd := rand.New(rand.NewSource(3))
+	d := Microfacet{Roughness: roughness}.distribution() // without the Kulla-Conty lobe, which isn't made of microfacets
+	var facets []float64
+	for i := 0; i < lobeSamples; i++ {
+		facets = append(facets, 1-d.sample(geom.Up, rnd).Y)
+	}
+	want := quartile(facets)
+	tr := Transmit{Specular: 0.04, Roughness: roughness, Multiplier: 1}
+	thin := ThinTransmit{Specular: 0.04, Roughness: roughness, Multiplier: 1}
+	pr := Principled{Color: rgb.White, Roughness: math.Sqrt(roughness), Transmission: 1, IOR: 1.5} // squared into alpha
+	lobes := map[string]float64{
+		"Transmit reflection": slope(tr, rnd, func(wi geom.Dir) (geom.Dir, bool) {
+			return geom.Up.Half(wi), wi.Y > 0
+		}),
+		"Transmit refraction": slope(tr, rnd, func(wi geom.Dir) (geom.Dir, bool) {
+			wm, _, ok := halfway(geom.Up, wi, tr.eta(geom.Up))
+			return wm, ok && wi.Y < 0
+		}),
+		"Principled refraction": slope(pr, rnd, func(wi geom.Dir) (geom.Dir, bool) {
+			wm, _, ok := halfway(geom.Up, wi, pr.transmitter().eta(geom.Up))
+			return wm, ok && wi.Y < 0
+		}),
+		"ThinTransmit transmission": slope(thin, rnd, func(wi geom.Dir) (geom.Dir, bool) {
+			return geom.Up.Half(geom.Dir{wi.X, -wi.Y, wi.Z}), wi.Y < 0
+		}),
+	}
+	for name, s := range lobes {
+		if math.Abs(s-want) > 0.1*want {
+			t.Errorf("Expected %v microfacets as steep as Microfacet's (%v), got %v", name, want, s)
+		}
+	}
+}
+
+// slope returns the lower quartile of 1 - cos of the microfacet normals that scatter light from straight above
+// into the directions that lobe accepts, skipping samples that contribute nothing.
+func slope(b render.BSDF, rnd *rand.Rand, lobe func(wi geom.Dir) (wm geom.Dir, ok bool)) float64 {
+	var slopes []float64
+	for i := 0; i < lobeSamples; i++ {
+		wi, pdf, _ := b.Sample(geom.Up, rnd)
+		if pdf <= 0 || math.IsInf(pdf, 1) {
+			continue // masked, with no contribution
+		}
+		if wm, ok := lobe(wi); ok {
+			slopes = append(slopes, 1-wm.Y)
+		}
+	}
+	return quartile(slopes)
+}
+
+// quartile returns the lower quartile of slopes, which it sorts.
+func quartile(slopes []float64) float64 {
+	sort.Float64s(slopes)
+	return slopes[len(slopes)/4]
+}
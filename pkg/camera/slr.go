@@ -75,6 +75,16 @@ func (s *SLR) Ray(x, y, width, height float64, rnd *rand.Rand) *geom.Ray {
 	return s.trans.MultRay(ray)
 }
 
+// PixelAngle returns the angle between the rays through neighboring pixels
+// of an image width x height pixels.
+func (s *SLR) PixelAngle(width, height float64) float64 {
+	sensor := s.Width
+	if aImage := width / height; aImage < s.Width/s.Height { // taller image; cropped horizontally
+		sensor = s.Height * aImage
+	}
+	return 2 * math.Atan(sensor/(2*s.Lens)) / width
+}
+
 func (s *SLR) transform() {
 	s.trans = geom.LookMatrix(s.position, s.target)
 }
@@ -3,7 +3,6 @@ package mtl
 import (
 	"bufio"
 	"fmt"
-	"image"
 	"io"
 	"math"
 	"os"
@@ -11,13 +10,10 @@ import (
 	"strconv"
 	"strings"
 
-	_ "image/jpeg"
-	_ "image/png"
-
-	_ "github.com/ftrvxmtrx/tga"
-
+	"github.com/hunterloftis/pbr/pkg/geom"
 	"github.com/hunterloftis/pbr/pkg/material"
 	"github.com/hunterloftis/pbr/pkg/rgb"
+	"github.com/hunterloftis/pbr/pkg/texture"
 )
 
 // http://exocortex.com/blog/extending_wavefront_mtl_to_support_pbr
@@ -33,17 +29,32 @@ func ReadFile(filename string, recursive bool) (map[string]*material.Mapped, err
 	return lib, nil
 }
 
-func readTexture(filename string) image.Image {
-	f, err := os.Open(filename)
+// readTexture returns the image in filename as a Texture, configured by the -s, -o and -clamp map options.
+// Color maps are sRGB-encoded; data maps are linear.
+func readTexture(filename string, opts mapOptions, filter texture.Filter, space texture.ColorSpace) texture.Texture {
+	tex, err := texture.Files.ReadFile(filename, space)
 	if err != nil {
-		fmt.Println("unable to open image:", filename, err)
+		fmt.Println("unable to read image:", filename, err)
+		return nil
 	}
-	defer f.Close()
-	im, _, err := image.Decode(f)
-	if err != nil {
-		fmt.Println("error decoding:", err)
+	tex.Transform.Scale = geom.Vec{opts.float("-s", 0, 1), opts.float("-s", 1, 1), 1}
+	tex.Transform.Offset = geom.Vec{opts.float("-o", 0, 0), opts.float("-o", 1, 0), 0}
+	tex.Sampler.Filter = filter
+	if len(opts["-clamp"]) > 0 && strings.ToLower(opts["-clamp"][0]) == "on" {
+		tex.Sampler.Wrap = texture.Clamp
 	}
-	return im
+	return tex
+}
+
+// remap returns a scalar Texture that applies fn to the mean of t, or nil if t is nil.
+func remap(t texture.Texture, fn func(float64) float64) texture.Texture {
+	if t == nil {
+		return nil
+	}
+	return texture.Func(func(c texture.Coord) rgb.Energy {
+		n := fn(t.At(c).Mean())
+		return rgb.Energy{n, n, n}
+	})
 }
 
 func Read(r io.Reader, dir string) map[string]*material.Mapped {
@@ -53,13 +64,26 @@ func Read(r io.Reader, dir string) map[string]*material.Mapped {
 		color        = "kd"
 		colorMap     = "map_kd"
 		transmit     = "tr"
+		filter       = "tf"
+		filterDist   = "td" // extension: the distance, in scene units, over which white light becomes Tf
 		invTransmit  = "d"
+		invTransMap  = "map_d"
 		invRoughness = "ns"
+		invRoughMap  = "map_ns"
 		roughMap     = "map_pr"
 		emit         = "ke"
+		emitMap      = "map_ke"
 		refraction   = "ni"
 		metal        = "pm"
+		metalMap     = "map_pm"
+		aniso        = "aniso"
+		anisoMap     = "map_aniso"
+		anisoRot     = "anisor"
+		anisoRotMap  = "map_anisor"
 		normal       = "norm"
+		displacement = "disp"
+		bump         = "bump"
+		bumpMap      = "map_bump"
 	)
 	scanner := bufio.NewScanner(r)
 	lib := make(map[string]*material.Mapped)
@@ -86,23 +110,46 @@ func Read(r io.Reader, dir string) map[string]*material.Mapped {
 			str := strings.Join(args, ",")
 			lib[current].Base.Color, _ = rgb.ParseEnergy(str)
 		case colorMap:
-			f := filepath.Join(dir, strings.Join(args, " "))
-			lib[current].Color = readTexture(f)
+			f, opts := parseMap(args)
+			lib[current].Color = readTexture(filepath.Join(dir, f), opts, texture.Trilinear, texture.SRGB)
 		case transmit:
 			if t, err := strconv.ParseFloat(args[0], 64); err == nil {
-				lib[current].Base.Transmission = math.Pow(t, 4)
+				lib[current].Base.Transmission = t
+			}
+		case filter:
+			// Tf tints light through each unit of the scene, unless a Td statement gives another distance.
+			str := strings.Join(args, ",")
+			if tf, err := rgb.ParseEnergy(str); err == nil {
+				lib[current].Base.AttenuationColor = tf
+				if lib[current].Base.AttenuationDistance == 0 {
+					lib[current].Base.AttenuationDistance = 1
+				}
+			}
+		case filterDist:
+			if d, err := strconv.ParseFloat(args[0], 64); err == nil {
+				lib[current].Base.AttenuationDistance = d
 			}
 		case invTransmit:
 			if d, err := strconv.ParseFloat(args[0], 64); err == nil {
-				lib[current].Base.Transmission = math.Pow((1 - d), 4)
+				lib[current].Base.Transmission = 1 - d
 			}
+		case invTransMap:
+			f, opts := parseMap(args)
+			lib[current].Transmission = remap(readTexture(filepath.Join(dir, f), opts, texture.Trilinear, texture.Linear), func(d float64) float64 {
+				return 1 - d
+			})
 		case invRoughness:
 			if ir, err := strconv.ParseFloat(args[0], 64); err == nil {
 				lib[current].Base.Roughness = 1 - (ir / 1000)
 			}
+		case invRoughMap:
+			f, opts := parseMap(args)
+			lib[current].Roughness = remap(readTexture(filepath.Join(dir, f), opts, texture.Trilinear, texture.Linear), func(ir float64) float64 {
+				return 1 - ir
+			})
 		case roughMap:
-			f := filepath.Join(dir, strings.Join(args, " "))
-			lib[current].Roughness = readTexture(f)
+			f, opts := parseMap(args)
+			lib[current].Roughness = readTexture(filepath.Join(dir, f), opts, texture.Trilinear, texture.Linear)
 		case emit:
 			str := strings.Join(args, ",")
 			if e, err := rgb.ParseEnergy(str); err == nil {
@@ -110,6 +157,10 @@ func Read(r io.Reader, dir string) map[string]*material.Mapped {
 					lib[current].Base.Color, lib[current].Base.Emission = e.Compressed(1)
 				}
 			}
+		case emitMap:
+			// map_Ke modulates Ke, so only materials with a Ke become lights; the rest keep reflecting.
+			f, opts := parseMap(args)
+			lib[current].Emission = readTexture(filepath.Join(dir, f), opts, texture.Trilinear, texture.SRGB)
 		case refraction:
 			if ior, err := strconv.ParseFloat(args[0], 64); err == nil {
 				if ior > 1 {
@@ -120,15 +171,120 @@ func Read(r io.Reader, dir string) map[string]*material.Mapped {
 			if m, err := strconv.ParseFloat(args[0], 64); err == nil {
 				lib[current].Base.Metalness = m
 			}
+		case metalMap:
+			f, opts := parseMap(args)
+			lib[current].Metalness = readTexture(filepath.Join(dir, f), opts, texture.Trilinear, texture.Linear)
+		case aniso:
+			if a, err := strconv.ParseFloat(args[0], 64); err == nil {
+				lib[current].Base.Anisotropic = a
+			}
+		case anisoMap:
+			f, opts := parseMap(args)
+			lib[current].Anisotropic = readTexture(filepath.Join(dir, f), opts, texture.Trilinear, texture.Linear)
+		case anisoRot:
+			if r, err := strconv.ParseFloat(args[0], 64); err == nil {
+				lib[current].Base.AnisotropicRotation = r
+			}
+		case anisoRotMap:
+			f, opts := parseMap(args)
+			// Filtering would average angles across the wrap from 1 to 0.
+			lib[current].AnisotropicRotation = readTexture(filepath.Join(dir, f), opts, texture.Nearest, texture.Linear)
 		case normal:
-			f := filepath.Join(dir, strings.Join(args, " "))
-			lib[current].Normal = readTexture(f)
+			f, opts := parseMap(args)
+			lib[current].Normal = readTexture(filepath.Join(dir, f), opts, texture.Trilinear, texture.Linear)
+		case bump, bumpMap:
+			f, opts := parseMap(args)
+			lib[current].Bump = readTexture(filepath.Join(dir, f), opts, texture.Bilinear, texture.Linear)
+			lib[current].BumpScale = opts.float("-bm", 0, 1)
+		case displacement:
+			f, opts := parseMap(args)
+			base, gain := opts.float("-mm", 0, 0), opts.float("-mm", 1, 1)
+			lib[current].Displacement = readTexture(filepath.Join(dir, f), opts, texture.Bilinear, texture.Linear)
+			lib[current].DisplaceScale = gain
+			if gain != 0 {
+				lib[current].DisplaceMid = -base / gain
+			}
 		}
 	}
 
+	// Tf and Td may come in either order, so attenuation is converted once each material is complete.
+	for _, m := range lib {
+		if m.Base != nil {
+			m.Base.Attenuate(m.Base.AttenuationColor, m.Base.AttenuationDistance)
+		}
+	}
 	return lib
 }
 
+// mapArgs is the maximum number of arguments taken by each texture map option.
+// http://paulbourke.net/dataformats/mtl/
+var mapArgs = map[string]int{
+	"-blendu":  1,
+	"-blendv":  1,
+	"-bm":      1,
+	"-boost":   1,
+	"-cc":      1,
+	"-clamp":   1,
+	"-imfchan": 1,
+	"-mm":      2,
+	"-o":       3,
+	"-s":       3,
+	"-t":       3,
+	"-texres":  1,
+	"-type":    1,
+}
+
+// mapWords are the texture map options whose argument is a word, like -clamp on, rather than a number.
+var mapWords = map[string]bool{
+	"-blendu":  true,
+	"-blendv":  true,
+	"-cc":      true,
+	"-clamp":   true,
+	"-imfchan": true,
+	"-type":    true,
+}
+
+// mapOptions holds the arguments of each option given to a texture map.
+type mapOptions map[string][]string
+
+// parseMap splits the arguments of a texture map statement into its options and filename.
+// Numeric options end at the first argument that isn't a number, so a filename is never taken as one.
+func parseMap(args []string) (filename string, opts mapOptions) {
+	opts = make(mapOptions)
+	for len(args) > 1 {
+		name := strings.ToLower(args[0])
+		n, ok := mapArgs[name]
+		if !ok {
+			break
+		}
+		args = args[1:]
+		vals := make([]string, 0, n)
+		for len(vals) < n && len(args) > 1 {
+			if !mapWords[name] {
+				if _, err := strconv.ParseFloat(args[0], 64); err != nil {
+					break
+				}
+			}
+			vals = append(vals, args[0])
+			args = args[1:]
+		}
+		opts[name] = vals
+	}
+	return strings.Join(args, " "), opts
+}
+
+// float returns the i'th argument of option name, or def if it is missing or invalid.
+func (o mapOptions) float(name string, i int, def float64) float64 {
+	if i >= len(o[name]) {
+		return def
+	}
+	f, err := strconv.ParseFloat(o[name][i], 64)
+	if err != nil {
+		return def
+	}
+	return f
+}
+
 // https://www.allegorithmic.com/system/files/software/download/build/PBR_Guide_Vol.1.pdf
 func fresnel0(ior float64) float64 {
 	return math.Pow(ior-1, 2) / math.Pow(ior+1, 2)
@@ -0,0 +1,50 @@
+package mtl
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMap(t *testing.T) {
+	tests := []struct {
+		line string
+		file string
+		opts mapOptions
+	}{
+		{"map_Kd wood.png", "wood.png", mapOptions{}},
+		{"map_Kd my wood.png", "my wood.png", mapOptions{}},
+		{"map_Kd -s 2 2 wood.png", "wood.png", mapOptions{"-s": {"2", "2"}}},
+		{"map_Kd -s 2 2 1 -o 0.5 0 0 wood.png", "wood.png", mapOptions{"-s": {"2", "2", "1"}, "-o": {"0.5", "0", "0"}}},
+		{"map_Kd -clamp on wood.png", "wood.png", mapOptions{"-clamp": {"on"}}},
+		{"map_Kd -blendu off -CLAMP on wood.png", "wood.png", mapOptions{"-blendu": {"off"}, "-clamp": {"on"}}},
+		{"map_d -imfchan m alpha.png", "alpha.png", mapOptions{"-imfchan": {"m"}}},
+		{"map_Ns -texres 512 rough.png", "rough.png", mapOptions{"-texres": {"512"}}},
+		{"bump -bm 0.5 bump.png", "bump.png", mapOptions{"-bm": {"0.5"}}},
+		{"disp -mm 0.1 height.png", "height.png", mapOptions{"-mm": {"0.1"}}},
+		{"disp -mm -0.5 2 height.png", "height.png", mapOptions{"-mm": {"-0.5", "2"}}},
+		{"disp -mm 0.1 2 3.png", "3.png", mapOptions{"-mm": {"0.1", "2"}}},
+		{"disp -mm height.png", "height.png", mapOptions{"-mm": {}}},
+		{"norm -unknown 1 normal.png", "-unknown 1 normal.png", mapOptions{}},
+		{"map_Kd -s 2", "2", mapOptions{"-s": {}}},
+	}
+	for _, test := range tests {
+		file, opts := parseMap(strings.Fields(test.line)[1:])
+		if file != test.file {
+			t.Errorf("%q: expected file %q, got %q", test.line, test.file, file)
+		}
+		if !reflect.DeepEqual(opts, test.opts) {
+			t.Errorf("%q: expected options %v, got %v", test.line, test.opts, opts)
+		}
+	}
+}
+
+func TestMapOptionFloat(t *testing.T) {
+	_, opts := parseMap(strings.Fields("-mm 0.1 height.png"))
+	if f := opts.float("-mm", 0, 0); f != 0.1 {
+		t.Error("Expected a base of 0.1, got", f)
+	}
+	if f := opts.float("-mm", 1, 1); f != 1 {
+		t.Error("Expected the default gain of 1, got", f)
+	}
+}
@@ -0,0 +1,208 @@
+package obj
+
+import (
+	"math"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/surface"
+	"github.com/hunterloftis/pbr/pkg/texture"
+)
+
+// maxTessellation limits how finely triangles are split:
+// no edge is split once it is shorter than the longest displaced edge divided by 2^maxTessellation.
+// Because that floor is the same for every edge, neighbors still agree on shared edges.
+// The recursion itself stops after maxDepth levels, which only degenerate slivers reach;
+// those may be left with T-junctions.
+const (
+	maxTessellation = 10
+	maxDepth        = 4 * maxTessellation
+)
+
+// displacer is a Material with a height channel, such as material.Mapped.
+type displacer interface {
+	Displace(c texture.Coord) (dist float64, ok bool)
+}
+
+// Displace tessellates triangles with displacement maps until no edge is longer than length,
+// then moves their vertices along their normals.
+// Vertex normals of the displaced triangles are regenerated.
+func (m *Mesh) Displace(length float64) *Mesh {
+	return m.displace(func(a, b geom.Vec) bool {
+		return b.Minus(a).Len() > length
+	})
+}
+
+// DisplaceFrom is Displace with camera-adaptive tessellation:
+// triangles are split until no edge subtends more than angle (in radians) as seen from eye.
+func (m *Mesh) DisplaceFrom(eye geom.Vec, angle float64) *Mesh {
+	return m.displace(func(a, b geom.Vec) bool {
+		a, b = m.mtx.MultPoint(a), m.mtx.MultPoint(b)
+		dist := a.Plus(b).Scaled(0.5).Minus(eye).Len()
+		return b.Minus(a).Len() > angle*dist
+	})
+}
+
+// displace splits each edge for which split returns true.
+// Because split depends only on an edge's endpoints, neighboring triangles split shared edges identically,
+// and because every copy of a position moves along the same direction, the displaced surface is free of cracks.
+// Texture seams can still open if the height differs on either side of them.
+// https://en.wikipedia.org/wiki/Displacement_mapping
+func (m *Mesh) displace(split func(a, b geom.Vec) bool) *Mesh {
+	kept := make([]*surface.Triangle, 0, len(m.Triangles))
+	source := make([]*surface.Triangle, 0)
+	for _, t := range m.Triangles {
+		d, ok := t.Mat.(displacer)
+		if !ok {
+			kept = append(kept, t)
+			continue
+		}
+		if _, ok := d.Displace(m.coord(t.Points[0], t.Texture[0])); !ok {
+			kept = append(kept, t)
+			continue
+		}
+		source = append(source, t)
+	}
+	if len(source) == 0 {
+		return m
+	}
+	floor := longest(source) / math.Exp2(maxTessellation)
+	limited := func(a, b geom.Vec) bool {
+		return b.Minus(a).Len() > floor && split(a, b)
+	}
+	dirs := directions(source)
+	moved := make([]*surface.Triangle, 0, len(source))
+	for _, t := range source {
+		d := t.Mat.(displacer)
+		corners := [3]vertex{}
+		for i := range corners {
+			corners[i] = vertex{t.Points[i], dirs[t.Points[i]], t.Texture[i]}
+		}
+		for _, c := range tessellate(corners, limited, maxDepth, nil) {
+			for i := range c {
+				dist, _ := d.Displace(m.coord(c[i].pt, c[i].tex))
+				c[i].pt = c[i].pt.Plus(c[i].norm.Scaled(dist))
+			}
+			tri := surface.NewTriangle(c[0].pt, c[1].pt, c[2].pt, t.Mat)
+			tri.SetTexture(c[0].tex, c[1].tex, c[2].tex)
+			moved = append(moved, tri)
+		}
+	}
+	c := (&Mesh{Triangles: moved, crease: m.crease}).cage()
+	for i := range c.faces {
+		c.faces[i].norms = nil
+	}
+	moved, polys := c.mesh(m.crease)
+	present := make(map[*surface.Triangle]bool, len(kept))
+	for _, t := range kept {
+		present[t] = true
+	}
+	for _, p := range m.polys {
+		if complete(p.tris, present) {
+			polys = append(polys, p)
+		}
+	}
+	m.Triangles, m.polys = append(kept, moved...), polys
+	return m
+}
+
+// coord returns the texture coordinate of a vertex at pt, in object space, with texture coordinates tex.
+func (m *Mesh) coord(pt, tex geom.Vec) texture.Coord {
+	return texture.Coord{
+		U:      tex.X,
+		V:      tex.Y,
+		World:  texture.Point{Pos: m.mtx.MultPoint(pt)},
+		Object: texture.Point{Pos: pt},
+	}
+}
+
+// directions welds the corners of tris by position and returns the direction each position is displaced along:
+// the sum of the face normals around it, weighted by the angle of each face at that corner.
+// Moving a shared position along each face's own normal would tear faceted meshes apart at their edges.
+func directions(tris []*surface.Triangle) map[geom.Vec]geom.Dir {
+	sums := make(map[geom.Vec]geom.Vec)
+	for _, t := range tris {
+		n, ok := t.Points[1].Minus(t.Points[0]).Cross(t.Points[2].Minus(t.Points[0])).Unit()
+		if !ok {
+			continue
+		}
+		for i, pt := range t.Points {
+			sums[pt] = sums[pt].Plus(geom.Vec(n).Scaled(cornerAngle(t, i)))
+		}
+	}
+	dirs := make(map[geom.Vec]geom.Dir, len(sums))
+	for pt, sum := range sums {
+		dirs[pt], _ = sum.Unit()
+	}
+	return dirs
+}
+
+// longest returns the length of the longest edge among tris.
+func longest(tris []*surface.Triangle) float64 {
+	l := 0.0
+	for _, t := range tris {
+		for i := range t.Points {
+			l = math.Max(l, t.Points[(i+1)%3].Minus(t.Points[i]).Len())
+		}
+	}
+	return l
+}
+
+// vertex is a triangle corner with its attributes.
+type vertex struct {
+	pt   geom.Vec
+	norm geom.Dir
+	tex  geom.Vec
+}
+
+func (a vertex) mid(b vertex) vertex {
+	n, _ := geom.Vec(a.norm).Plus(geom.Vec(b.norm)).Unit()
+	return vertex{
+		pt:   a.pt.Plus(b.pt).Scaled(0.5),
+		norm: n,
+		tex:  a.tex.Plus(b.tex).Scaled(0.5),
+	}
+}
+
+// tessellate appends to out the triangles that result from recursively splitting v.
+// Triangles with one or two split edges are bisected (red-green refinement).
+func tessellate(v [3]vertex, split func(a, b geom.Vec) bool, depth int, out [][3]vertex) [][3]vertex {
+	s := [3]bool{}
+	n := 0
+	for i := range v {
+		if s[i] = split(v[i].pt, v[(i+1)%3].pt); s[i] {
+			n++
+		}
+	}
+	if n == 0 || depth == 0 {
+		return append(out, v)
+	}
+	depth--
+	if n == 3 {
+		ab, bc, ca := v[0].mid(v[1]), v[1].mid(v[2]), v[2].mid(v[0])
+		out = tessellate([3]vertex{v[0], ab, ca}, split, depth, out)
+		out = tessellate([3]vertex{ab, v[1], bc}, split, depth, out)
+		out = tessellate([3]vertex{ca, bc, v[2]}, split, depth, out)
+		return tessellate([3]vertex{ab, bc, ca}, split, depth, out)
+	}
+	// rotate so that edge ab is split and, for two splits, bc is the other
+	r := 0
+	for i := range s {
+		if s[i] && (n == 1 || s[(i+1)%3]) {
+			r = i
+		}
+	}
+	a, b, c := v[r], v[(r+1)%3], v[(r+2)%3]
+	ab := a.mid(b)
+	if n == 1 {
+		out = tessellate([3]vertex{a, ab, c}, split, depth, out)
+		return tessellate([3]vertex{ab, b, c}, split, depth, out)
+	}
+	bc := b.mid(c)
+	out = tessellate([3]vertex{ab, b, bc}, split, depth, out)
+	if a.pt.Minus(bc.pt).Len() < c.pt.Minus(ab.pt).Len() {
+		out = tessellate([3]vertex{a, ab, bc}, split, depth, out)
+		return tessellate([3]vertex{a, bc, c}, split, depth, out)
+	}
+	out = tessellate([3]vertex{a, ab, c}, split, depth, out)
+	return tessellate([3]vertex{ab, bc, c}, split, depth, out)
+}
@@ -0,0 +1,89 @@
+package obj
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/material"
+	"github.com/hunterloftis/pbr/pkg/texture"
+)
+
+// cube is a faceted unit cube whose faces don't share texture coordinates.
+const cube = `
+v -1 -1 -1
+v 1 -1 -1
+v 1 1 -1
+v -1 1 -1
+v -1 -1 1
+v 1 -1 1
+v 1 1 1
+v -1 1 1
+vt 0 0
+vt 1 0
+vt 1 1
+vt 0 1
+f 1/1 4/2 3/3 2/4
+f 5/1 6/2 7/3 8/4
+f 1/1 2/2 6/3 5/4
+f 2/1 3/2 7/3 6/4
+f 3/1 4/2 8/3 7/4
+f 4/1 1/2 5/3 8/4
+`
+
+// raised displaces every point by the same height.
+type raised struct {
+	*material.Uniform
+}
+
+func (r raised) Displace(c texture.Coord) (float64, bool) {
+	return 0.25, true
+}
+
+func TestDisplaceWatertight(t *testing.T) {
+	m := raisedCube()
+	m.Displace(0.5)
+	if n := openEdges(m); n != 0 {
+		t.Error("Expected a closed mesh, got", n, "open edges")
+	}
+}
+
+func TestDisplaceFromWatertight(t *testing.T) {
+	// with the eye almost touching a corner, splits near it stop at the edge floor rather than at split
+	m := raisedCube()
+	m.DisplaceFrom(geom.Vec{1.0001, 1.0001, 1.0001}, 0.1)
+	if n := openEdges(m); n != 0 {
+		t.Error("Expected a closed mesh, got", n, "open edges")
+	}
+}
+
+func raisedCube() *Mesh {
+	m := Read(strings.NewReader(cube), "")
+	for _, t := range m.Triangles {
+		t.Mat = raised{material.Plastic(1, 1, 1, 0.5)}
+	}
+	return m
+}
+
+// openEdges counts the edges of m that aren't shared by exactly two triangles.
+// A closed mesh with neither cracks nor T-junctions has none.
+func openEdges(m *Mesh) int {
+	type edge struct{ a, b geom.Vec }
+	count := make(map[edge]int)
+	for _, t := range m.Triangles {
+		for i := range t.Points {
+			a, b := t.Points[i], t.Points[(i+1)%3]
+			if b.X < a.X || (b.X == a.X && (b.Y < a.Y || (b.Y == a.Y && b.Z < a.Z))) {
+				a, b = b, a
+			}
+			count[edge{a, b}]++
+		}
+	}
+	open := 0
+	for _, n := range count {
+		if n != 2 {
+			open++
+		}
+	}
+	return open
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/hunterloftis/pbr/pkg/render"
 	"github.com/hunterloftis/pbr/pkg/rgb"
 	"github.com/hunterloftis/pbr/pkg/surface"
+	"github.com/hunterloftis/pbr/pkg/texture"
 )
 
 type Material struct {
@@ -14,14 +15,14 @@ type Material struct {
 	Files []string
 }
 
-func (m *Material) At(u, v float64, in, norm geom.Dir, rnd *rand.Rand) (geom.Dir, render.BSDF) {
-	return norm, surface.Lambert{}
+func (m *Material) At(c texture.Coord, in, norm geom.Dir, rnd *rand.Rand) (geom.Dir, render.BSDF) {
+	return geom.Up, surface.Lambert{}
 }
 
 func (m *Material) Light() rgb.Energy {
 	return rgb.Black
 }
 
-func (m *Material) Transmit() rgb.Energy {
+func (m *Material) Absorb() rgb.Energy {
 	return rgb.Black
 }
@@ -1,6 +1,8 @@
 package obj
 
 import (
+	"math"
+
 	"github.com/hunterloftis/pbr/pkg/geom"
 	"github.com/hunterloftis/pbr/pkg/render"
 	"github.com/hunterloftis/pbr/pkg/surface"
@@ -8,13 +10,23 @@ import (
 
 type Mesh struct {
 	Triangles []*surface.Triangle
+	polys     []polygon
+	crease    float64
 	mtx       *geom.Mtx
 	mat       *surface.Material
 }
 
+// polygon groups the Triangles that were fanned from a single polygon.
+type polygon struct {
+	tris    []*surface.Triangle
+	group   int  // smoothing group, or 0 for none
+	normals bool // vertex normals were supplied rather than generated
+}
+
 func NewMesh() *Mesh {
 	return &Mesh{
-		mtx: geom.Identity(),
+		crease: math.Pi,
+		mtx:    geom.Identity(),
 	}
 }
 
@@ -40,6 +52,14 @@ func (m *Mesh) SetMaterial(mat surface.Material) *Mesh {
 	return m
 }
 
+// SetCrease sets the angle (in radians) between adjacent faces above which their shared edge is sharp.
+// Vertex normals generated from smoothing groups are regenerated with it.
+func (m *Mesh) SetCrease(angle float64) *Mesh {
+	m.crease = angle
+	m.smoothGroups()
+	return m
+}
+
 func (m *Mesh) Scale(v geom.Vec) *Mesh {
 	m.mtx = m.mtx.Mult(geom.Scale(v))
 	return m
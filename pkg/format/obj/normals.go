@@ -0,0 +1,135 @@
+package obj
+
+import (
+	"math"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/surface"
+)
+
+// ComputeNormals replaces the Mesh's vertex normals with the average normals of the faces around each vertex,
+// weighted by the angle each face makes at the vertex.
+// Faces are only smoothed together if they share a smoothing group and meet at no more than creaseAngle (in radians).
+// http://www.bytehazard.com/articles/vertnorm.html
+func (m *Mesh) ComputeNormals(creaseAngle float64) *Mesh {
+	polys := m.polygons()
+	for i := range polys {
+		polys[i].normals = false
+	}
+	smooth(polys, creaseAngle)
+	m.polys = polys
+	return m
+}
+
+// smoothGroups generates the vertex normals of polygons in smoothing groups that weren't supplied any.
+func (m *Mesh) smoothGroups() {
+	grouped := make([]polygon, 0)
+	for _, p := range m.polygons() {
+		if p.group != 0 && !p.normals {
+			grouped = append(grouped, p)
+		}
+	}
+	smooth(grouped, m.crease)
+}
+
+// polygons returns the Mesh's polygons.
+// Triangles that are no longer part of a complete polygon are returned on their own.
+func (m *Mesh) polygons() []polygon {
+	present := make(map[*surface.Triangle]bool, len(m.Triangles))
+	for _, t := range m.Triangles {
+		present[t] = true
+	}
+	owner := make(map[*surface.Triangle]int)
+	for i, p := range m.polys {
+		for _, t := range p.tris {
+			owner[t] = i
+		}
+	}
+	polys := make([]polygon, 0, len(m.polys))
+	done := make(map[*surface.Triangle]bool, len(m.Triangles))
+	for _, t := range m.Triangles {
+		if done[t] {
+			continue
+		}
+		p := polygon{tris: []*surface.Triangle{t}, normals: !flat(t)}
+		if i, ok := owner[t]; ok && complete(m.polys[i].tris, present) {
+			p = m.polys[i]
+		}
+		for _, t := range p.tris {
+			done[t] = true
+		}
+		polys = append(polys, p)
+	}
+	return polys
+}
+
+func complete(tris []*surface.Triangle, present map[*surface.Triangle]bool) bool {
+	for _, t := range tris {
+		if !present[t] {
+			return false
+		}
+	}
+	return true
+}
+
+// flat returns true if every vertex normal of t is its face normal.
+func flat(t *surface.Triangle) bool {
+	n, _ := t.Points[1].Minus(t.Points[0]).Cross(t.Points[2].Minus(t.Points[0])).Unit()
+	for _, tn := range t.Normals {
+		if tn.Dot(n) < 1-1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+// smooth sets the vertex normals of polys from the polygons around each vertex
+// that share its smoothing group and meet it at no more than crease.
+func smooth(polys []polygon, crease float64) {
+	type corner struct {
+		poly  int
+		angle float64
+	}
+	normals := make([]geom.Dir, len(polys))
+	around := make(map[geom.Vec][]corner)
+	for i, p := range polys {
+		var n geom.Vec
+		for _, t := range p.tris {
+			n = n.Plus(t.Points[1].Minus(t.Points[0]).Cross(t.Points[2].Minus(t.Points[0])))
+			for j, pt := range t.Points {
+				around[pt] = append(around[pt], corner{i, cornerAngle(t, j)})
+			}
+		}
+		normals[i], _ = n.Unit()
+	}
+	cos := math.Cos(crease)
+	for i, p := range polys {
+		for _, t := range p.tris {
+			var ns [3]geom.Dir
+			for j, pt := range t.Points {
+				var sum geom.Vec
+				for _, c := range around[pt] {
+					q := polys[c.poly]
+					if c.poly == i || q.group == p.group && normals[c.poly].Dot(normals[i]) >= cos {
+						sum = sum.Plus(normals[c.poly].Scaled(c.angle))
+					}
+				}
+				var ok bool
+				if ns[j], ok = sum.Unit(); !ok {
+					ns[j] = normals[i]
+				}
+			}
+			t.SetNormals(ns[0], ns[1], ns[2])
+		}
+	}
+}
+
+// cornerAngle returns the interior angle of t at vertex i.
+func cornerAngle(t *surface.Triangle, i int) float64 {
+	a, ok1 := t.Points[(i+1)%3].Minus(t.Points[i]).Unit()
+	b, ok2 := t.Points[(i+2)%3].Minus(t.Points[i]).Unit()
+	if !ok1 || !ok2 {
+		return 0
+	}
+	return math.Acos(math.Max(-1, math.Min(1, a.Dot(b))))
+}
@@ -0,0 +1,40 @@
+package obj
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// smoothCube is cube in a single smoothing group.
+const smoothCube = "s 1\n" + cube
+
+func TestSmoothingGroup(t *testing.T) {
+	m := Read(strings.NewReader(smoothCube), "")
+	if n := flats(m); n != 0 {
+		t.Error("Expected every triangle to be smoothed, got", n, "flat triangles")
+	}
+}
+
+func TestSmoothingGroupCrease(t *testing.T) {
+	// the cube's faces meet at 90 degrees, so a 60 degree crease keeps every edge sharp
+	m := Read(strings.NewReader(smoothCube), "").SetCrease(math.Pi / 3)
+	if n := flats(m); n != len(m.Triangles) {
+		t.Error("Expected", len(m.Triangles), "flat triangles, got", n)
+	}
+	m.SetCrease(math.Pi)
+	if n := flats(m); n != 0 {
+		t.Error("Expected every triangle to be smoothed again, got", n, "flat triangles")
+	}
+}
+
+// flats counts the triangles of m whose vertex normals are all their face normal.
+func flats(m *Mesh) int {
+	n := 0
+	for _, t := range m.Triangles {
+		if flat(t) {
+			n++
+		}
+	}
+	return n
+}
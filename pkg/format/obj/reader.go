@@ -16,7 +16,6 @@ import (
 )
 
 // TODO: make robust
-// TODO: support smoothing groups (s)
 
 func ReadFile(filename string, recursive bool) (*Mesh, error) {
 	f, err := os.Open(filename)
@@ -86,12 +85,13 @@ func (t *tablegroup) tex(i int) geom.Vec {
 
 func Read(r io.Reader, dir string) *Mesh {
 	const (
-		vertex   = "v"
-		normal   = "vn"
-		texture  = "vt"
-		face     = "f"
-		library  = "mtllib"
-		material = "usemtl"
+		vertex    = "v"
+		normal    = "vn"
+		texture   = "vt"
+		face      = "f"
+		library   = "mtllib"
+		material  = "usemtl"
+		smoothing = "s"
 	)
 
 	mesh := NewMesh()
@@ -99,6 +99,7 @@ func Read(r io.Reader, dir string) *Mesh {
 	mat := &Material{}
 	mats := make(map[string]*Material)
 	libs := make([]string, 0)
+	group := 0
 	scanner := bufio.NewScanner(r)
 
 	for scanner.Scan() {
@@ -129,18 +130,24 @@ func Read(r io.Reader, dir string) *Mesh {
 			}
 			table.tt = append(table.tt, t)
 		case face:
-			tris, err := newTriangles(args, table, mat)
+			p, err := newPolygon(args, table, mat)
 			if err != nil {
 				panic(err)
 			}
-			mesh.Triangles = append(mesh.Triangles, tris...)
+			p.group = group
+			mesh.Triangles = append(mesh.Triangles, p.tris...)
+			mesh.polys = append(mesh.polys, p)
 		case library:
 			libs = append(libs, strings.Join(args, " "))
 		case material:
 			mat = newMaterial(args, mats)
+		case smoothing:
+			group, _ = parseInt(args[0]) // "off" is group 0
 		}
 	}
 
+	mesh.smoothGroups()
+
 	for _, mat := range mats {
 		mat.Files = make([]string, len(libs))
 		for i, lib := range libs {
@@ -180,10 +187,11 @@ func newMaterial(args []string, mats map[string]*Material) *Material {
 	return mats[name]
 }
 
-func newTriangles(args []string, table *tablegroup, mat *Material) ([]*surface.Triangle, error) {
+func newPolygon(args []string, table *tablegroup, mat *Material) (polygon, error) {
+	p := polygon{}
 	size := len(args)
 	if size < 3 {
-		return nil, fmt.Errorf("face requires at least 3 vertices (contains %v)", size)
+		return p, fmt.Errorf("face requires at least 3 vertices (contains %v)", size)
 	}
 	verts := make([]geom.Vec, 0)
 	norms := make([]geom.Dir, 0)
@@ -207,9 +215,9 @@ func newTriangles(args []string, table *tablegroup, mat *Material) ([]*surface.T
 		}
 	}
 	if len(verts) != size {
-		return nil, fmt.Errorf("face vertex size != arg list size")
+		return p, fmt.Errorf("face vertex size != arg list size")
 	}
-	tris := make([]*surface.Triangle, 0)
+	p.normals = len(norms) == size
 	for i := 2; i < size; i++ {
 		tri := surface.NewTriangle(verts[0], verts[i-1], verts[i], mat)
 		if len(norms) == size {
@@ -218,9 +226,9 @@ func newTriangles(args []string, table *tablegroup, mat *Material) ([]*surface.T
 		if len(texes) == size {
 			tri.SetTexture(texes[0], texes[i-1], texes[i])
 		}
-		tris = append(tris, tri)
+		p.tris = append(p.tris, tri)
 	}
-	return tris, nil
+	return p, nil
 }
 
 func parseInt(str string) (int, error) {
@@ -0,0 +1,351 @@
+package obj
+
+import (
+	"math"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/surface"
+)
+
+// Subdivide smooths the Mesh with levels of Loop subdivision (if every face is a triangle)
+// or Catmull-Clark subdivision (otherwise).
+// Boundary edges, and edges between faces that meet at more than the crease angle, stay sharp.
+// UVs and vertex normals are interpolated across each face;
+// faces without vertex normals have them generated after subdivision.
+// https://graphics.pixar.com/opensubdiv/docs/subdivision_surfaces.html
+func (m *Mesh) Subdivide(levels int) *Mesh {
+	if levels < 1 || len(m.Triangles) == 0 {
+		return m
+	}
+	c := m.cage()
+	loop := c.triangular()
+	for i := 0; i < levels; i++ {
+		if loop {
+			c = c.loop()
+		} else {
+			c = c.catmullClark()
+		}
+	}
+	m.Triangles, m.polys = c.mesh(m.crease)
+	return m
+}
+
+// cage is a polygonal control mesh with shared vertices.
+type cage struct {
+	verts []geom.Vec
+	faces []cageFace
+	sharp map[edge]bool
+}
+
+type cageFace struct {
+	verts []int
+	tex   []geom.Vec
+	norms []geom.Dir // nil when normals should be generated
+	mat   surface.Material
+	group int
+}
+
+// edge connects two vertices, lowest index first.
+type edge [2]int
+
+func newEdge(a, b int) edge {
+	if a > b {
+		a, b = b, a
+	}
+	return edge{a, b}
+}
+
+// topology describes the connectivity of a cage.
+type topology struct {
+	edges  []edge         // every edge, in order of discovery
+	faces  map[edge][]int // faces around each edge
+	vedges [][]edge       // edges around each vertex
+	vfaces [][]int        // faces around each vertex
+}
+
+// cage welds the Mesh's triangles by position and regroups them into their original polygons.
+func (m *Mesh) cage() *cage {
+	c := &cage{sharp: make(map[edge]bool)}
+	index := make(map[geom.Vec]int)
+	weld := func(v geom.Vec) int {
+		i, ok := index[v]
+		if !ok {
+			i = len(c.verts)
+			index[v] = i
+			c.verts = append(c.verts, v)
+		}
+		return i
+	}
+	for _, p := range m.polygons() {
+		f := cageFace{mat: p.tris[0].Mat, group: p.group}
+		add := func(t *surface.Triangle, i int) {
+			f.verts = append(f.verts, weld(t.Points[i]))
+			f.tex = append(f.tex, t.Texture[i])
+			f.norms = append(f.norms, t.Normals[i])
+		}
+		for j, t := range p.tris {
+			if j == 0 {
+				add(t, 0)
+				add(t, 1)
+			}
+			add(t, 2)
+		}
+		if !p.normals {
+			f.norms = nil
+		}
+		c.faces = append(c.faces, f)
+	}
+	t := c.topology()
+	for _, e := range t.edges {
+		faces := t.faces[e]
+		if len(faces) != 2 {
+			c.sharp[e] = true
+			continue
+		}
+		n0, _ := c.area(c.faces[faces[0]]).Unit()
+		n1, _ := c.area(c.faces[faces[1]]).Unit()
+		if math.Acos(math.Max(-1, math.Min(1, n0.Dot(n1)))) > m.crease {
+			c.sharp[e] = true
+		}
+	}
+	return c
+}
+
+func (c *cage) triangular() bool {
+	for _, f := range c.faces {
+		if len(f.verts) != 3 {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *cage) topology() *topology {
+	t := &topology{
+		faces:  make(map[edge][]int),
+		vedges: make([][]edge, len(c.verts)),
+		vfaces: make([][]int, len(c.verts)),
+	}
+	for i, f := range c.faces {
+		for j, v := range f.verts {
+			e := newEdge(v, f.verts[(j+1)%len(f.verts)])
+			if _, ok := t.faces[e]; !ok {
+				t.edges = append(t.edges, e)
+				t.vedges[e[0]] = append(t.vedges[e[0]], e)
+				t.vedges[e[1]] = append(t.vedges[e[1]], e)
+			}
+			t.faces[e] = append(t.faces[e], i)
+			t.vfaces[v] = append(t.vfaces[v], i)
+		}
+	}
+	return t
+}
+
+// area returns the normal of face f scaled by twice its area (Newell's method).
+func (c *cage) area(f cageFace) geom.Vec {
+	var n geom.Vec
+	for i, v := range f.verts {
+		a, b := c.verts[v], c.verts[f.verts[(i+1)%len(f.verts)]]
+		n = n.Plus(a.Cross(b))
+	}
+	return n
+}
+
+func (c *cage) isSharp(e edge, t *topology) bool {
+	return c.sharp[e] || len(t.faces[e]) != 2
+}
+
+// vertexPoint applies the crease and corner rules to vertex v.
+// It returns false if v is smooth and needs the scheme's own rule.
+func (c *cage) vertexPoint(v int, t *topology) (geom.Vec, bool) {
+	pt := c.verts[v]
+	ends := make([]geom.Vec, 0, 2)
+	for _, e := range t.vedges[v] {
+		if c.isSharp(e, t) {
+			ends = append(ends, c.verts[e[0]+e[1]-v])
+		}
+	}
+	switch {
+	case len(t.vedges[v]) < 3 && len(ends) < 2:
+		return pt, true
+	case len(ends) > 2, len(ends) == 2 && len(t.vfaces[v]) == 1:
+		return pt, true
+	case len(ends) == 2:
+		return pt.Scaled(0.75).Plus(ends[0].Plus(ends[1]).Scaled(0.125)), true
+	}
+	return pt, false
+}
+
+func (c *cage) midpoint(e edge) geom.Vec {
+	return c.verts[e[0]].Plus(c.verts[e[1]]).Scaled(0.5)
+}
+
+// split returns the next level's sharp edges, given the point inserted on each edge.
+func (c *cage) split(t *topology, points map[edge]int) map[edge]bool {
+	sharp := make(map[edge]bool)
+	for _, e := range t.edges {
+		if c.isSharp(e, t) {
+			sharp[newEdge(e[0], points[e])] = true
+			sharp[newEdge(points[e], e[1])] = true
+		}
+	}
+	return sharp
+}
+
+// catmullClark returns the next level of Catmull-Clark subdivision, in which every face becomes quads.
+// https://en.wikipedia.org/wiki/Catmull%E2%80%93Clark_subdivision_surface
+func (c *cage) catmullClark() *cage {
+	t := c.topology()
+	nv, ne := len(c.verts), len(t.edges)
+	verts := make([]geom.Vec, nv+ne+len(c.faces))
+	centers := verts[nv+ne:]
+	for i, f := range c.faces {
+		for _, v := range f.verts {
+			centers[i] = centers[i].Plus(c.verts[v])
+		}
+		centers[i] = centers[i].Scaled(1 / float64(len(f.verts)))
+	}
+	points := make(map[edge]int, ne)
+	for i, e := range t.edges {
+		points[e] = nv + i
+		verts[nv+i] = c.midpoint(e)
+		if !c.isSharp(e, t) {
+			faces := t.faces[e]
+			sum := c.verts[e[0]].Plus(c.verts[e[1]]).Plus(centers[faces[0]]).Plus(centers[faces[1]])
+			verts[nv+i] = sum.Scaled(0.25)
+		}
+	}
+	for v := range c.verts {
+		if pt, ok := c.vertexPoint(v, t); ok {
+			verts[v] = pt
+			continue
+		}
+		var q, r geom.Vec
+		for _, f := range t.vfaces[v] {
+			q = q.Plus(centers[f])
+		}
+		for _, e := range t.vedges[v] {
+			r = r.Plus(c.midpoint(e))
+		}
+		n := float64(len(t.vedges[v]))
+		q = q.Scaled(1 / float64(len(t.vfaces[v])))
+		r = r.Scaled(1 / n)
+		verts[v] = q.Plus(r.Scaled(2)).Plus(c.verts[v].Scaled(n - 3)).Scaled(1 / n)
+	}
+	next := &cage{verts: verts, sharp: c.split(t, points)}
+	for i, f := range c.faces {
+		k := len(f.verts)
+		all := make([]int, k)
+		for j := range all {
+			all[j] = j
+		}
+		center := nv + ne + i
+		for j, v := range f.verts {
+			prev, after := (j+k-1)%k, (j+1)%k
+			quad := []int{v, points[newEdge(v, f.verts[after])], center, points[newEdge(f.verts[prev], v)]}
+			next.faces = append(next.faces, f.child(quad, []int{j}, []int{j, after}, all, []int{prev, j}))
+		}
+	}
+	return next
+}
+
+// loop returns the next level of Loop subdivision, in which every triangle becomes four.
+// https://en.wikipedia.org/wiki/Loop_subdivision_surface
+func (c *cage) loop() *cage {
+	t := c.topology()
+	nv, ne := len(c.verts), len(t.edges)
+	verts := make([]geom.Vec, nv+ne)
+	points := make(map[edge]int, ne)
+	for i, e := range t.edges {
+		points[e] = nv + i
+		verts[nv+i] = c.midpoint(e)
+		if !c.isSharp(e, t) {
+			sum := c.verts[e[0]].Plus(c.verts[e[1]]).Scaled(3)
+			for _, f := range t.faces[e] {
+				for _, v := range c.faces[f].verts {
+					if v != e[0] && v != e[1] {
+						sum = sum.Plus(c.verts[v])
+					}
+				}
+			}
+			verts[nv+i] = sum.Scaled(0.125)
+		}
+	}
+	for v := range c.verts {
+		if pt, ok := c.vertexPoint(v, t); ok {
+			verts[v] = pt
+			continue
+		}
+		n := float64(len(t.vedges[v]))
+		beta := 3.0 / 16
+		if n > 3 {
+			beta = 3 / (8 * n)
+		}
+		pt := c.verts[v].Scaled(1 - n*beta)
+		for _, e := range t.vedges[v] {
+			pt = pt.Plus(c.verts[e[0]+e[1]-v].Scaled(beta))
+		}
+		verts[v] = pt
+	}
+	next := &cage{verts: verts, sharp: c.split(t, points)}
+	for _, f := range c.faces {
+		a, b, cc := f.verts[0], f.verts[1], f.verts[2]
+		ab, bc, ca := points[newEdge(a, b)], points[newEdge(b, cc)], points[newEdge(cc, a)]
+		next.faces = append(next.faces,
+			f.child([]int{a, ab, ca}, []int{0}, []int{0, 1}, []int{2, 0}),
+			f.child([]int{ab, b, bc}, []int{0, 1}, []int{1}, []int{1, 2}),
+			f.child([]int{ca, bc, cc}, []int{2, 0}, []int{1, 2}, []int{2}),
+			f.child([]int{ab, bc, ca}, []int{0, 1}, []int{1, 2}, []int{2, 0}),
+		)
+	}
+	return next
+}
+
+// child returns a face with vertices verts.
+// Each child corner's attributes average those of parent corners.
+func (f cageFace) child(verts []int, corners ...[]int) cageFace {
+	g := cageFace{verts: verts, tex: make([]geom.Vec, len(corners)), mat: f.mat, group: f.group}
+	if f.norms != nil {
+		g.norms = make([]geom.Dir, len(corners))
+	}
+	for i, cs := range corners {
+		var tex, norm geom.Vec
+		for _, j := range cs {
+			tex = tex.Plus(f.tex[j])
+			if f.norms != nil {
+				norm = norm.Plus(geom.Vec(f.norms[j]))
+			}
+		}
+		g.tex[i] = tex.Scaled(1 / float64(len(cs)))
+		if f.norms != nil {
+			g.norms[i], _ = norm.Unit()
+		}
+	}
+	return g
+}
+
+// mesh fans each face into triangles.
+// Missing vertex normals are generated with the crease angle.
+func (c *cage) mesh(crease float64) ([]*surface.Triangle, []polygon) {
+	tris := make([]*surface.Triangle, 0, len(c.faces)*2)
+	polys := make([]polygon, 0, len(c.faces))
+	generate := make([]polygon, 0)
+	for _, f := range c.faces {
+		p := polygon{group: f.group, normals: f.norms != nil}
+		for j := 2; j < len(f.verts); j++ {
+			tri := surface.NewTriangle(c.verts[f.verts[0]], c.verts[f.verts[j-1]], c.verts[f.verts[j]], f.mat)
+			if f.norms != nil {
+				tri.SetNormals(f.norms[0], f.norms[j-1], f.norms[j])
+			}
+			tri.SetTexture(f.tex[0], f.tex[j-1], f.tex[j])
+			p.tris = append(p.tris, tri)
+		}
+		tris = append(tris, p.tris...)
+		polys = append(polys, p)
+		if !p.normals {
+			generate = append(generate, p)
+		}
+	}
+	smooth(generate, crease)
+	return tris, polys
+}
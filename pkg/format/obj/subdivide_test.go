@@ -0,0 +1,100 @@
+package obj
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+)
+
+// tetrahedron is a regular tetrahedron centered on the origin.
+const tetrahedron = `
+v 1 1 1
+v 1 -1 -1
+v -1 1 -1
+v -1 -1 1
+f 1 2 3
+f 1 4 2
+f 1 3 4
+f 2 4 3
+`
+
+func TestCatmullClarkClosed(t *testing.T) {
+	m := Read(strings.NewReader(cube), "").Subdivide(2)
+	if n := openEdges(m); n != 0 {
+		t.Error("Expected a closed mesh, got", n, "open edges")
+	}
+}
+
+func TestCatmullClarkCorner(t *testing.T) {
+	// (face points + 2 * edge midpoints + (n - 3) * the corner) / n, with n = 3 faces around each corner
+	m := Read(strings.NewReader(cube), "").Subdivide(1)
+	if want := (geom.Vec{5.0 / 9, 5.0 / 9, 5.0 / 9}); !hasVertex(m, want) {
+		t.Error("Expected a corner at", want)
+	}
+	if hasVertex(m, geom.Vec{1, 1, 1}) {
+		t.Error("Expected the corner to move")
+	}
+}
+
+func TestLoopTetrahedron(t *testing.T) {
+	m := Read(strings.NewReader(tetrahedron), "").Subdivide(1)
+	if len(m.Triangles) != 16 {
+		t.Error("Expected 16 triangles, got", len(m.Triangles))
+	}
+	if n := openEdges(m); n != 0 {
+		t.Error("Expected a closed mesh, got", n, "open edges")
+	}
+	// 7/16 of the vertex plus 3/16 of each neighbor, which sum to minus the vertex
+	if want := (geom.Vec{0.25, 0.25, 0.25}); !hasVertex(m, want) {
+		t.Error("Expected a vertex at", want)
+	}
+	// 3/8 of each end plus 1/8 of each opposite vertex
+	if want := (geom.Vec{0.5, 0, 0}); !hasVertex(m, want) {
+		t.Error("Expected an edge point at", want)
+	}
+}
+
+func TestCreasedEdges(t *testing.T) {
+	// the cube's faces meet at 90 degrees, so a 45 degree crease makes every edge sharp
+	m := Read(strings.NewReader(cube), "").SetCrease(math.Pi / 4).Subdivide(2)
+	if n := openEdges(m); n != 0 {
+		t.Error("Expected a closed mesh, got", n, "open edges")
+	}
+	for _, want := range []geom.Vec{{1, 1, 1}, {1, 1, 0}, {1, 1, 0.5}, {-1, 1, -0.5}} {
+		if !hasVertex(m, want) {
+			t.Error("Expected a vertex on the creased edge at", want)
+		}
+	}
+	// every vertex near an edge of the cube lies on it
+	for _, tri := range m.Triangles {
+		for _, p := range tri.Points {
+			a := p.Abs()
+			near := 0
+			for _, c := range []float64{a.X, a.Y, a.Z} {
+				if c > 0.99 {
+					near++
+					if math.Abs(c-1) > 1e-9 {
+						t.Fatal("Expected", p, "to lie on the cube's surface")
+					}
+				}
+			}
+			if near == 0 {
+				t.Fatal("Expected", p, "to lie on the cube's surface")
+			}
+		}
+	}
+}
+
+// hasVertex returns whether any triangle of m has a vertex at v.
+func hasVertex(m *Mesh, v geom.Vec) bool {
+	for _, t := range m.Triangles {
+		for _, p := range t.Points {
+			if p.Minus(v).Len() < 1e-9 {
+				return true
+			}
+		}
+	}
+	return false
+}
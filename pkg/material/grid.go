@@ -8,6 +8,7 @@ import (
 	"github.com/hunterloftis/pbr/pkg/render"
 	"github.com/hunterloftis/pbr/pkg/rgb"
 	"github.com/hunterloftis/pbr/pkg/surface"
+	"github.com/hunterloftis/pbr/pkg/texture"
 )
 
 type Grid struct {
@@ -26,19 +27,19 @@ func NewGrid(base, line surface.Material, tiles int, thickness float64) *Grid {
 	}
 }
 
-func (g *Grid) At(u, v float64, in, norm geom.Dir, rnd *rand.Rand) (normal geom.Dir, bsdf render.BSDF) {
-	du := math.Mod(u, g.spacing)
-	dv := math.Mod(v, g.spacing)
+func (g *Grid) At(c texture.Coord, in, norm geom.Dir, rnd *rand.Rand) (normal geom.Dir, bsdf render.BSDF) {
+	du := math.Mod(c.U, g.spacing)
+	dv := math.Mod(c.V, g.spacing)
 	if du < g.radius || dv < g.radius {
-		return g.line.At(u, v, in, norm, rnd)
+		return g.line.At(c, in, norm, rnd)
 	}
-	return g.base.At(u, v, in, norm, rnd)
+	return g.base.At(c, in, norm, rnd)
 }
 
 func (g *Grid) Light() rgb.Energy {
 	return rgb.Black
 }
 
-func (g *Grid) Transmit() rgb.Energy {
+func (g *Grid) Absorb() rgb.Energy {
 	return rgb.Black
 }
@@ -1,21 +1,43 @@
 package material
 
 import (
-	"image"
-	"image/color"
 	"math/rand"
 
 	"github.com/hunterloftis/pbr/pkg/geom"
 	"github.com/hunterloftis/pbr/pkg/render"
 	"github.com/hunterloftis/pbr/pkg/rgb"
+	"github.com/hunterloftis/pbr/pkg/texture"
 )
 
+// bumpDelta is the texture coordinate distance used to differentiate Bump textures without a resolution.
+const bumpDelta = 1.0 / 1024
+
+// Mapped is a Uniform material whose parameters can vary across a surface.
+// Each Texture, when present, replaces the matching Base parameter.
+// Scalar parameters use the mean of the Texture's channels.
 type Mapped struct {
-	Color     image.Image
-	Metalness image.Image
-	Roughness image.Image
-	Normal    image.Image
-	Base      *Uniform
+	Color               texture.Texture
+	Metalness           texture.Texture
+	Roughness           texture.Texture
+	Specularity         texture.Texture
+	Emission            texture.Texture // multiplies Base.Light()
+	Transmission        texture.Texture
+	Anisotropic         texture.Texture
+	AnisotropicRotation texture.Texture // rotates the highlight by each texel's mean, 0-1 for a full turn
+	FilmThickness       texture.Texture // multiplies Base.FilmThickness
+	Translucency        texture.Texture
+	TranslucentColor    texture.Texture
+	Normal              texture.Texture
+	Base                *Uniform
+
+	NormalDirectX bool // Normal uses the DirectX convention, with green pointing down
+
+	Bump      texture.Texture
+	BumpScale float64 // multiplies the slope of Bump, in height per pixel
+
+	Displacement  texture.Texture
+	DisplaceScale float64 // distance moved along the normal at full height
+	DisplaceMid   float64 // height that leaves the surface in place
 }
 
 func NewMapped(base *Uniform) *Mapped {
@@ -25,58 +47,105 @@ func NewMapped(base *Uniform) *Mapped {
 	return &m
 }
 
-func colToEnergy(c color.Color) rgb.Energy {
-	r, g, b, _ := c.RGBA()
-	return rgb.Energy{float64(r) / 65535, float64(g) / 65535, float64(b) / 65535}
+func (m *Mapped) At(c texture.Coord, in, norm geom.Dir, rnd *rand.Rand) (normal geom.Dir, bsdf render.BSDF) {
+	sample := *m.Base
+	if m.Color != nil {
+		sample.Color = m.Color.At(c)
+	}
+	scalar(&sample.Metalness, m.Metalness, c)
+	scalar(&sample.Roughness, m.Roughness, c)
+	scalar(&sample.Specularity, m.Specularity, c)
+	scalar(&sample.Transmission, m.Transmission, c)
+	scalar(&sample.Anisotropic, m.Anisotropic, c)
+	scalar(&sample.AnisotropicRotation, m.AnisotropicRotation, c)
+	if m.FilmThickness != nil {
+		sample.FilmThickness *= m.FilmThickness.At(c).Mean()
+	}
+	scalar(&sample.Translucency, m.Translucency, c)
+	if m.TranslucentColor != nil {
+		sample.TranslucentColor = m.TranslucentColor.At(c)
+	}
+	_, bsdf = sample.At(c, in, norm, rnd)
+	if m.Normal != nil {
+		return decodeNormal(m.Normal.At(c), m.NormalDirectX), bsdf
+	}
+	if m.Bump != nil {
+		return m.bump(c), bsdf
+	}
+	return geom.Up, bsdf
 }
 
-func colToFloat(c color.Color) float64 {
-	r, g, b, _ := c.RGBA()
-	return float64(r+g+b) / (65535 * 3) // TODO: Need to take the length / square root here?
+// scalar replaces *f with the value of t at c, if t is present.
+func scalar(f *float64, t texture.Texture, c texture.Coord) {
+	if t != nil {
+		*f = t.At(c).Mean()
+	}
 }
 
-func (m *Mapped) At(u, v float64, in, norm geom.Dir, rnd *rand.Rand) (normal geom.Dir, bsdf render.BSDF) {
-	sample := *m.Base
-	img := image.Image(nil)
-	x := 0
-	y := 0
-	if m.Color != nil {
-		img = m.Color
-	} else if m.Roughness != nil {
-		img = m.Roughness
+// decodeNormal decodes a tangent-space normal map value.
+// Red points along u and green along v, or against v in the DirectX convention.
+// https://docs.unity3d.com/Manual/StandardShaderMaterialParameterNormalMap.html
+func decodeNormal(e rgb.Energy, directX bool) geom.Dir {
+	x, y, z := e.X*2-1, e.Y*2-1, e.Z*2-1
+	if directX {
+		y = -y
 	}
-	if img != nil {
-		w := img.Bounds().Max.X
-		h := img.Bounds().Max.Y
-		u2 := u * float64(w)
-		v2 := -v * float64(h)
-		x = int(u2) % w
-		if x < 0 {
-			x += w
-		}
-		y = int(v2) % h
-		if y < 0 {
-			y += h
-		}
+	n, ok := geom.Vec{x, z, y}.Unit()
+	if !ok {
+		return geom.Up
 	}
-	if m.Color != nil {
-		sample.Color = colToEnergy(m.Color.At(x, y))
+	return n
+}
+
+// bump returns the tangent-space normal of the height field Bump at c
+// from central differences one pixel apart.
+// https://en.wikipedia.org/wiki/Bump_mapping
+func (m *Mapped) bump(c texture.Coord) geom.Dir {
+	du, dv := bumpDelta, bumpDelta
+	if s, ok := m.Bump.(interface{ Size() (w, h int) }); ok {
+		w, h := s.Size()
+		du, dv = 1/float64(w), 1/float64(h)
 	}
-	// if m.Metalness != nil {
-	// 	sample.Metalness = colToFloat(m.Metalness.At(x, y))
-	// }
-	if m.Roughness != nil {
-		sample.Roughness = colToFloat(m.Roughness.At(x, y))
+	height := func(du, dv float64) float64 {
+		s := c
+		s.U, s.V = c.U+du, c.V+dv
+		return m.Bump.At(s).Mean()
 	}
-	_, bsdf = sample.At(u, v, in, norm, rnd)
-	// TODO: combine normal map at u,v with norm
-	return geom.Up, bsdf
+	dhdu := (height(du, 0) - height(-du, 0)) / 2
+	dhdv := (height(0, dv) - height(0, -dv)) / 2
+	n, _ := geom.Vec{-dhdu * m.BumpScale, 1, -dhdv * m.BumpScale}.Unit()
+	return n
+}
+
+// Displace returns the distance to move the surface along its normal at c.
+// It returns false if m has no Displacement.
+func (m *Mapped) Displace(c texture.Coord) (float64, bool) {
+	if m.Displacement == nil {
+		return 0, false
+	}
+	h := m.Displacement.At(c).Mean()
+	return (h - m.DisplaceMid) * m.DisplaceScale, true
+}
+
+func (m *Mapped) TwoSided() bool {
+	if m.Transmission != nil && !m.Base.Thin {
+		return false // may transmit into a volume, as in Uniform
+	}
+	return m.Base.TwoSided()
 }
 
 func (m *Mapped) Light() rgb.Energy {
 	return m.Base.Light()
 }
 
-func (m *Mapped) Transmit() rgb.Energy {
-	return m.Base.Transmit()
+// LightAt returns the light emitted at c.
+func (m *Mapped) LightAt(c texture.Coord) rgb.Energy {
+	if m.Emission == nil {
+		return m.Light()
+	}
+	return m.Emission.At(c).Times(m.Light())
+}
+
+func (m *Mapped) Absorb() rgb.Energy {
+	return m.Base.Absorb()
 }
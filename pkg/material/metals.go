@@ -1,6 +1,9 @@
 package material
 
-import "github.com/hunterloftis/pbr/pkg/rgb"
+import (
+	"github.com/hunterloftis/pbr/pkg/bsdf"
+	"github.com/hunterloftis/pbr/pkg/rgb"
+)
 
 // https://i.stack.imgur.com/Q73nz.png
 
@@ -27,3 +30,71 @@ func Copper(roughness, metalness float64) *Uniform {
 		Roughness: roughness,
 	}
 }
+
+// Measured metals, with the complex refractive index of each at about 650, 550, and 450nm.
+// https://refractiveindex.info
+
+func Aluminium(roughness float64) *Uniform {
+	return Conductor(bsdf.Conductor{
+		Eta: rgb.Energy{1.657, 0.880, 0.521},
+		K:   rgb.Energy{9.224, 6.270, 4.837},
+	}, roughness)
+}
+
+func Silver(roughness float64) *Uniform {
+	return Conductor(bsdf.Conductor{
+		Eta: rgb.Energy{0.155, 0.117, 0.138},
+		K:   rgb.Energy{4.828, 3.122, 2.147},
+	}, roughness)
+}
+
+func Chrome(roughness float64) *Uniform {
+	return Conductor(bsdf.Conductor{
+		Eta: rgb.Energy{3.105, 3.182, 2.423},
+		K:   rgb.Energy{3.310, 3.330, 3.270},
+	}, roughness)
+}
+
+func Titanium(roughness float64) *Uniform {
+	return Conductor(bsdf.Conductor{
+		Eta: rgb.Energy{2.741, 2.541, 2.267},
+		K:   rgb.Energy{3.814, 3.435, 3.039},
+	}, roughness)
+}
+
+func Brass(roughness float64) *Uniform {
+	return Conductor(bsdf.Conductor{
+		Eta: rgb.Energy{0.444, 0.527, 1.094},
+		K:   rgb.Energy{3.695, 2.765, 1.829},
+	}, roughness)
+}
+
+func Iron(roughness float64) *Uniform {
+	return Conductor(bsdf.Conductor{
+		Eta: rgb.Energy{2.911, 2.950, 2.585},
+		K:   rgb.Energy{3.089, 2.932, 2.767},
+	}, roughness)
+}
+
+func Platinum(roughness float64) *Uniform {
+	return Conductor(bsdf.Conductor{
+		Eta: rgb.Energy{2.375, 2.085, 1.845},
+		K:   rgb.Energy{4.265, 3.716, 3.137},
+	}, roughness)
+}
+
+// Conductor returns a metal that reflects with the Fresnel equations of c.
+// Its Color is c's reflectance at normal incidence.
+func Conductor(c bsdf.Conductor, roughness float64) *Uniform {
+	return &Uniform{
+		Color:     c.Reflectance(1),
+		Metalness: 1,
+		Roughness: roughness,
+		Fresnel:   c,
+	}
+}
+
+// EdgeTint returns a metal that reflects color at normal incidence, tending toward edge at grazing angles.
+func EdgeTint(color, edge rgb.Energy, roughness float64) *Uniform {
+	return Conductor(bsdf.EdgeTint(color, edge), roughness)
+}
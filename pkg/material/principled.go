@@ -0,0 +1,97 @@
+package material
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/hunterloftis/pbr/pkg/bsdf"
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/render"
+	"github.com/hunterloftis/pbr/pkg/rgb"
+	"github.com/hunterloftis/pbr/pkg/texture"
+)
+
+// Principled is a material with the parameters of Blender's Principled BSDF and Substance's PBR shaders.
+// Unlike Uniform, it weighs its lobes by their Fresnel reflectance and albedo.
+type Principled struct {
+	Color               rgb.Energy
+	Metallic            float64
+	Roughness           float64
+	Specular            float64 // 0.5 reflects 4% at normal incidence
+	SpecularTint        float64
+	Anisotropic         float64
+	AnisotropicRotation float64 // 0-1, a full turn from the direction of increasing u
+	Sheen               float64
+	SheenTint           float64
+	Clearcoat           float64
+	ClearcoatRoughness  float64
+	Transmission        float64
+	AttenuationColor    rgb.Energy // of white light after it travels AttenuationDistance within the volume, as in Uniform
+	AttenuationDistance float64
+	IOR                 float64
+	Priority            int // of its volume where it overlaps others, as in Uniform
+	Emission            float64
+
+	attenuation attenuation
+}
+
+// NewPrincipled returns a Principled material with Blender's defaults.
+func NewPrincipled(color rgb.Energy) *Principled {
+	return &Principled{
+		Color:              color,
+		Roughness:          0.5,
+		Specular:           0.5,
+		SheenTint:          0.5,
+		ClearcoatRoughness: 0.03,
+		IOR:                1.45,
+	}
+}
+
+func (p *Principled) At(c texture.Coord, in, norm geom.Dir, rnd *rand.Rand) (geom.Dir, render.BSDF) {
+	if in.Dot(norm) > 0 {
+		if p.Transmission == 0 {
+			return geom.Up, bsdf.Ignore{}
+		}
+		specular := math.Pow((p.IOR-1)/(p.IOR+1), 2)
+		alpha := p.Roughness * p.Roughness // the alpha that bsdf.Principled squares its Roughness into
+		if alpha < bsdf.Smooth {
+			return geom.Up, bsdf.Boundary{BSDF: bsdf.Glass{Specular: specular, Multiplier: 1}, Rank: p.Priority}
+		}
+		t := bsdf.Transmit{Specular: specular, Roughness: alpha, Multiplier: 1}
+		return geom.Up, bsdf.Boundary{BSDF: t, Rank: p.Priority}
+	}
+	b := bsdf.Principled{
+		Color:              p.Color,
+		Metallic:           p.Metallic,
+		Roughness:          p.Roughness,
+		Specular:           p.Specular,
+		SpecularTint:       p.SpecularTint,
+		Anisotropic:        p.Anisotropic,
+		Rotation:           p.AnisotropicRotation * 2 * math.Pi,
+		Sheen:              p.Sheen,
+		SheenTint:          p.SheenTint,
+		Clearcoat:          p.Clearcoat,
+		ClearcoatRoughness: p.ClearcoatRoughness,
+		Transmission:       p.Transmission,
+		IOR:                p.IOR,
+	}
+	if p.Transmission == 0 {
+		return geom.Up, b
+	}
+	return geom.Up, bsdf.Boundary{BSDF: b, Rank: p.Priority}
+}
+
+func (p *Principled) Light() rgb.Energy {
+	return p.Color.Scaled(p.Emission)
+}
+
+func (p *Principled) Absorb() rgb.Energy {
+	return p.attenuation.coefficients(p.AttenuationColor, p.AttenuationDistance)
+}
+
+// Attenuate sets the AttenuationColor and AttenuationDistance of p and converts them once, as in Uniform.
+func (p *Principled) Attenuate(color rgb.Energy, distance float64) *Principled {
+	p.AttenuationColor, p.AttenuationDistance = color, distance
+	p.attenuation = attenuation{color: color, distance: distance, absorb: Attenuation(color, distance)}
+	return p
+}
@@ -0,0 +1,61 @@
+package material
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/hunterloftis/pbr/pkg/bsdf"
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/render"
+	"github.com/hunterloftis/pbr/pkg/rgb"
+	"github.com/hunterloftis/pbr/pkg/texture"
+)
+
+// boundaryAt returns the Boundary of p where a ray along in strikes a surface with normal geom.Up.
+func boundaryAt(t *testing.T, p *Principled, in geom.Dir) render.Boundary {
+	_, b := p.At(texture.Coord{}, in, geom.Up, rand.New(rand.NewSource(1)))
+	bo, ok := b.(render.Boundary)
+	if !ok {
+		t.Fatalf("Expected a render.Boundary, got %T", b)
+	}
+	return bo
+}
+
+func TestPrincipledNested(t *testing.T) {
+	// a glass holding water, which the glass fills where they overlap
+	glass := &Principled{Color: rgb.White, Transmission: 1, IOR: 1.5, Priority: 1}
+	water := &Principled{Color: rgb.White, Transmission: 1, IOR: 1.33}
+	down, up := geom.Dir{0, -1, 0}, geom.Up
+
+	for _, in := range []geom.Dir{down, up} {
+		if g := boundaryAt(t, glass, in); g.Priority() != 1 || math.Abs(g.IOR()-1.5) > 1e-9 {
+			t.Error("Expected the glass to have priority 1 and IOR 1.5, got", g.Priority(), g.IOR())
+		}
+		if w := boundaryAt(t, water, in); w.Priority() != 0 || math.Abs(w.IOR()-1.33) > 1e-9 {
+			t.Error("Expected the water to have priority 0 and IOR 1.33, got", w.Priority(), w.IOR())
+		}
+	}
+
+	// water leaving into water, as through an overlapping second volume of it, matches indices and disappears
+	if b := boundaryAt(t, water, up).Outside(1.33); b.(bsdf.Boundary).BSDF != (bsdf.Ignore{}) {
+		t.Errorf("Expected water within water to be ignored, got %T", b.(bsdf.Boundary).BSDF)
+	}
+
+	// entering the water from the glass, rays bend by the ratio of their indices rather than by the water's own
+	wo := geom.Dir{0.6, 0.8, 0}
+	b := boundaryAt(t, water, down).Outside(1.5)
+	rnd := rand.New(rand.NewSource(2))
+	for i := 0; i < 100; i++ {
+		wi, _, _ := b.Sample(wo, rnd)
+		if wi.Y >= 0 {
+			continue
+		}
+		sinO, sinI := wo.X, -wi.X
+		if want := sinO * 1.5 / 1.33; math.Abs(sinI-want) > 1e-9 {
+			t.Fatal("Expected a refracted sine of", want, "got", sinI)
+		}
+		return
+	}
+	t.Fatal("Expected the water to refract")
+}
@@ -0,0 +1,70 @@
+package material
+
+import (
+	"math/rand"
+
+	"github.com/hunterloftis/pbr/pkg/bsdf"
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/medium"
+	"github.com/hunterloftis/pbr/pkg/render"
+	"github.com/hunterloftis/pbr/pkg/rgb"
+	"github.com/hunterloftis/pbr/pkg/texture"
+)
+
+// Subsurface is a translucent material, like skin, wax, or marble, that scatters light beneath its surface.
+// Light refracts through a rough dielectric boundary, then takes a random walk through the interior
+// until it is absorbed or leaves, so Subsurface should cover closed meshes.
+// Albedo, MeanFreePath, and Anisotropy describe the interior, as in medium.Homogeneous;
+// MeanFreePath is in scene units.
+type Subsurface struct {
+	Albedo       rgb.Energy
+	MeanFreePath rgb.Energy
+	Anisotropy   float64
+	Roughness    float64
+	Specularity  float64
+	Priority     int // where it overlaps other volumes, as in Uniform
+}
+
+func NewSubsurface(albedo, meanFreePath rgb.Energy) *Subsurface {
+	return &Subsurface{
+		Albedo:       albedo,
+		MeanFreePath: meanFreePath,
+		Roughness:    0.3,
+		Specularity:  0.04,
+	}
+}
+
+// Measured scattering coefficients, with the scale of a millimeter in scene units.
+// http://graphics.stanford.edu/papers/bssrdf/bssrdf.pdf
+
+func Marble(scale float64) *Subsurface {
+	return measured(rgb.Energy{2.19, 2.62, 3.00}, rgb.Energy{0.0021, 0.0041, 0.0071}, scale)
+}
+
+func Skin(scale float64) *Subsurface {
+	return measured(rgb.Energy{0.74, 0.88, 1.01}, rgb.Energy{0.032, 0.17, 0.48}, scale)
+}
+
+// measured returns the Subsurface with reduced scattering and absorption coefficients
+// per millimeter, at scale scene units per millimeter.
+func measured(scattering, absorption rgb.Energy, scale float64) *Subsurface {
+	h := medium.NewHomogeneous(scattering, absorption, 0)
+	return NewSubsurface(h.Albedo, h.MeanFreePath.Scaled(scale))
+}
+
+func (s *Subsurface) At(c texture.Coord, in, norm geom.Dir, rnd *rand.Rand) (geom.Dir, render.BSDF) {
+	interior := medium.Homogeneous{Albedo: s.Albedo, MeanFreePath: s.MeanFreePath, Anisotropy: s.Anisotropy}
+	var boundary render.BSDF = bsdf.Transmit{Specular: s.Specularity, Roughness: s.Roughness, Multiplier: 1}
+	if s.Roughness < bsdf.Smooth {
+		boundary = bsdf.Glass{Specular: s.Specularity, Multiplier: 1}
+	}
+	return geom.Up, bsdf.Boundary{BSDF: boundary, Medium: interior, Rank: s.Priority}
+}
+
+func (s *Subsurface) Light() rgb.Energy {
+	return rgb.Black
+}
+
+func (s *Subsurface) Absorb() rgb.Energy {
+	return rgb.Black
+}
@@ -1,21 +1,59 @@
 package material
 
-import "github.com/hunterloftis/pbr/pkg/rgb"
+import (
+	"math"
+
+	"github.com/hunterloftis/pbr/pkg/rgb"
+)
+
+// minAttenuation is the least fraction of light that Attenuation lets through, to keep its coefficients finite.
+const minAttenuation = 1e-6
 
 func Glass(roughness float64) *Uniform {
 	return &Uniform{
 		Color:        rgb.Energy{1, 1, 1},
 		Roughness:    roughness,
 		Specularity:  0.042,
-		Transmission: 0.91339,
+		Transmission: 1,
 	}
 }
 
+// ColoredGlass tints the light that travels through each unit of the scene within it to r, g, b.
 func ColoredGlass(r, g, b, roughness float64) *Uniform {
-	return &Uniform{
+	return (&Uniform{
 		Color:        rgb.Energy{r, g, b},
 		Roughness:    roughness,
 		Specularity:  0.042,
-		Transmission: 0.91339, // https://www.shimadzu.com/an/industry/electronicselectronic/chem0501005.htm
+		Transmission: 1,
+	}).Attenuate(rgb.Energy{r, g, b}, 1)
+}
+
+// Attenuation returns the coefficients of absorption, per scene unit, of a volume in which
+// white light becomes color after traveling distance, like the attenuationColor and attenuationDistance of glTF.
+// Volumes with zero or infinite distance absorb nothing.
+// https://github.com/KhronosGroup/glTF/tree/main/extensions/2.0/Khronos/KHR_materials_volume
+func Attenuation(color rgb.Energy, distance float64) rgb.Energy {
+	if distance <= 0 || math.IsInf(distance, 1) {
+		return rgb.Black
+	}
+	absorb := func(c float64) float64 {
+		return -math.Log(math.Min(1, math.Max(minAttenuation, c))) / distance
+	}
+	return rgb.Energy{X: absorb(color.X), Y: absorb(color.Y), Z: absorb(color.Z)}
+}
+
+// attenuation is an AttenuationColor and AttenuationDistance converted into coefficients of absorption.
+type attenuation struct {
+	color    rgb.Energy
+	distance float64
+	absorb   rgb.Energy
+}
+
+// coefficients returns the coefficients of absorption for color and distance,
+// without converting them again if they're the ones a holds.
+func (a attenuation) coefficients(color rgb.Energy, distance float64) rgb.Energy {
+	if a.color == color && a.distance == distance {
+		return a.absorb
 	}
+	return Attenuation(color, distance)
 }
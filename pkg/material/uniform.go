@@ -1,59 +1,62 @@
 package material
 
 import (
+	"math"
 	"math/rand"
 
 	"github.com/hunterloftis/pbr/pkg/bsdf"
 	"github.com/hunterloftis/pbr/pkg/geom"
 	"github.com/hunterloftis/pbr/pkg/render"
 	"github.com/hunterloftis/pbr/pkg/rgb"
+	"github.com/hunterloftis/pbr/pkg/texture"
 )
 
 const reflect = 1.0 / 2.0
 const refract = 1 - reflect
 
 type Uniform struct {
-	Color        rgb.Energy
-	Metalness    float64
-	Roughness    float64
-	Specularity  float64 // TODO: consider renaming to "F0" or "Fresnel0"
-	Emission     float64
-	Transmission float64 // TODO: scale this non-linearly so a 0-1 range is more natural (since 0.0001% - 100% is a "normal" range)
+	Color               rgb.Energy
+	Metalness           float64
+	Roughness           float64
+	Specularity         float64 // TODO: consider renaming to "F0" or "Fresnel0"
+	Emission            float64
+	Anisotropic         float64      // stretches metallic and specular highlights along AnisotropicRotation, such as on brushed metal
+	AnisotropicRotation float64      // 0-1, a full turn from the direction of increasing u
+	Transmission        float64      // 0-1, the fraction of non-metallic light that refracts into the volume rather than scattering from the surface
+	AttenuationColor    rgb.Energy   // of white light after it travels AttenuationDistance within the volume
+	AttenuationDistance float64      // in scene units, or zero for a volume that absorbs nothing
+	Fresnel             bsdf.Fresnel // the metallic reflectance, such as a measured conductor, in place of Color
+	FilmThickness       float64      // of an iridescent film over metallic and specular reflections, in nanometers, or zero for none
+	FilmIOR             float64      // of the film, such as 1.33 for soap or 1.5 for oil, or zero for 1.5
+	Priority            int          // of a transmissive volume where it overlaps others, such as a glass (1) around the water (0) it holds
+	Thin                bool         // a sheet with no inside, like a leaf or a window, through which Transmission passes straight, without refraction or attenuation
+	Translucency        float64      // 0-1, the fraction of diffuse light that scatters through to the other side, as through paper or a lampshade
+	TranslucentColor    rgb.Energy   // of the light that Translucency scatters through
+	DoubleSided         bool         // faces both ways, for single-sided meshes, unless it transmits into a volume; Thin materials always do
+
+	attenuation attenuation
 }
 
-func (un *Uniform) At(u, v float64, in, norm geom.Dir, rnd *rand.Rand) (geom.Dir, render.BSDF) {
+func (un *Uniform) At(c texture.Coord, in, norm geom.Dir, rnd *rand.Rand) (geom.Dir, render.BSDF) {
 	cos := in.Dot(norm)
 	if cos > 0 {
 		if un.Transmission == 0 {
 			return geom.Up, bsdf.Ignore{} // TODO: doesn't seem to be working, have similar code in the trace() fn
 		}
-		return geom.Up, bsdf.Transmit{
-			Specular:   un.Specularity,
-			Roughness:  un.Roughness,
-			Multiplier: 1,
-		}
+		return geom.Up, un.transmitter()
 	}
 	if rnd.Float64() <= un.Metalness {
-		return geom.Up, bsdf.Microfacet{
-			Specular:   un.Color,
-			Roughness:  un.Roughness,
-			Multiplier: 1,
-		}
+		return geom.Up, un.reflector(un.Color, un.metallic(), 1)
 	}
-	// TODO: dynamic reflect/refract ratio based on material properties
+	if rnd.Float64() < un.Transmission {
+		return geom.Up, un.transmitter()
+	}
+	// TODO: dynamic reflect/diffuse ratio based on material properties
 	if rnd.Float64() < reflect {
-		return geom.Up, bsdf.Microfacet{
-			Specular:   rgb.Energy{un.Specularity, un.Specularity, un.Specularity},
-			Roughness:  un.Roughness,
-			Multiplier: 1 / reflect,
-		}
+		return geom.Up, un.reflector(rgb.Energy{un.Specularity, un.Specularity, un.Specularity}, un.dielectric(), 1/reflect)
 	}
-	if un.Transmission > 0 {
-		return geom.Up, bsdf.Transmit{
-			Specular:   un.Specularity,
-			Roughness:  un.Roughness,
-			Multiplier: 1 / refract,
-		}
+	if rnd.Float64() < un.Translucency {
+		return geom.Up, bsdf.Translucent{Color: un.TranslucentColor, Multiplier: 1 / refract}
 	}
 	return geom.Up, bsdf.Lambert{
 		Color:      un.Color,
@@ -61,10 +64,97 @@ func (un *Uniform) At(u, v float64, in, norm geom.Dir, rnd *rand.Rand) (geom.Dir
 	}
 }
 
+// TwoSided returns whether rays strike the front of the surface from either side.
+// A transmissive volume has to know which side is inside, so it only faces both ways when Thin.
+func (un *Uniform) TwoSided() bool {
+	if un.Transmission > 0 {
+		return un.Thin
+	}
+	return un.DoubleSided || un.Thin
+}
+
+// metallic returns the Fresnel reflectance of the metal, beneath the film if there is one.
+// Metals without a measured Fresnel are approximated from their Color.
+func (un *Uniform) metallic() bsdf.Fresnel {
+	if un.FilmThickness <= 0 {
+		return un.Fresnel
+	}
+	base, ok := un.Fresnel.(bsdf.Conductor)
+	if !ok {
+		base = bsdf.EdgeTint(un.Color, un.Color)
+	}
+	return un.film(base)
+}
+
+// dielectric returns the Fresnel reflectance of the specular layer beneath the film, or nil if there is no film.
+func (un *Uniform) dielectric() bsdf.Fresnel {
+	if un.FilmThickness <= 0 {
+		return nil
+	}
+	f0 := math.Sqrt(math.Min(0.99, un.Specularity))
+	ior := (1 + f0) / (1 - f0)
+	base := bsdf.Conductor{Eta: rgb.Energy{ior, ior, ior}}
+	return un.film(base)
+}
+
+// film returns the Fresnel reflectance of base beneath the film, which is oil-like unless FilmIOR is set.
+func (un *Uniform) film(base bsdf.Conductor) bsdf.Fresnel {
+	ior := un.FilmIOR
+	if ior <= 0 {
+		ior = 1.5
+	}
+	return bsdf.ThinFilm{Thickness: un.FilmThickness, IOR: ior, Base: base}
+}
+
+// reflector returns a specular BSDF, perfectly smooth below bsdf.Smooth roughness.
+func (un *Uniform) reflector(specular rgb.Energy, fresnel bsdf.Fresnel, multiplier float64) render.BSDF {
+	if un.Roughness < bsdf.Smooth {
+		return bsdf.Mirror{Specular: specular, Multiplier: multiplier, Fresnel: fresnel}
+	}
+	return bsdf.Microfacet{
+		Specular:    specular,
+		Roughness:   un.Roughness,
+		Anisotropic: un.Anisotropic,
+		Rotation:    un.AnisotropicRotation * 2 * math.Pi,
+		Multiplier:  multiplier,
+		Fresnel:     fresnel,
+	}
+}
+
+// transmitter returns the dielectric boundary of a volume, or a Thin sheet, perfectly smooth below bsdf.Smooth roughness.
+func (un *Uniform) transmitter() render.BSDF {
+	if un.Thin {
+		if un.Roughness < bsdf.Smooth {
+			return bsdf.ThinGlass{Specular: un.Specularity, Multiplier: 1}
+		}
+		return bsdf.ThinTransmit{Specular: un.Specularity, Roughness: un.Roughness, Multiplier: 1}
+	}
+	if un.Roughness < bsdf.Smooth {
+		return bsdf.Boundary{BSDF: bsdf.Glass{Specular: un.Specularity, Multiplier: 1}, Rank: un.Priority}
+	}
+	t := bsdf.Transmit{
+		Specular:   un.Specularity,
+		Roughness:  un.Roughness,
+		Multiplier: 1,
+	}
+	return bsdf.Boundary{BSDF: t, Rank: un.Priority}
+}
+
 func (un *Uniform) Light() rgb.Energy {
 	return un.Color.Scaled(un.Emission)
 }
 
-func (un *Uniform) Transmit() rgb.Energy {
-	return un.Color.Scaled(un.Transmission)
+func (un *Uniform) Absorb() rgb.Energy {
+	if un.Thin {
+		return rgb.Black
+	}
+	return un.attenuation.coefficients(un.AttenuationColor, un.AttenuationDistance)
+}
+
+// Attenuate sets the AttenuationColor and AttenuationDistance of un and converts them into coefficients of absorption once,
+// rather than on every hit.
+func (un *Uniform) Attenuate(color rgb.Energy, distance float64) *Uniform {
+	un.AttenuationColor, un.AttenuationDistance = color, distance
+	un.attenuation = attenuation{color: color, distance: distance, absorb: Attenuation(color, distance)}
+	return un
 }
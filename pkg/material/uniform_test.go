@@ -0,0 +1,53 @@
+package material
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hunterloftis/pbr/pkg/bsdf"
+	"github.com/hunterloftis/pbr/pkg/rgb"
+)
+
+func TestFilmWithoutIOR(t *testing.T) {
+	metal := Gold(0.2, 0)
+	metal.FilmThickness = 300
+	plastic := Plastic(1, 1, 1, 0.2)
+	plastic.FilmThickness = 300
+	for _, f := range []bsdf.Fresnel{metal.metallic(), plastic.dielectric()} {
+		for _, cos := range []float64{0, 0.5, 1} {
+			r := f.Reflectance(cos)
+			for _, c := range []float64{r.X, r.Y, r.Z} {
+				if math.IsNaN(c) || c < 0 || c > 1 {
+					t.Fatal("Expected a reflectance from 0 to 1 at cos", cos, "got", r)
+				}
+			}
+		}
+	}
+}
+
+func TestDoubleSidedVolume(t *testing.T) {
+	glass := Glass(0)
+	glass.DoubleSided = true
+	if glass.TwoSided() {
+		t.Error("Expected double-sided glass to keep its inside")
+	}
+	glass.Thin = true
+	if !glass.TwoSided() {
+		t.Error("Expected thin glass to face both ways")
+	}
+}
+
+func TestAttenuation(t *testing.T) {
+	// white light that keeps half its red over 2 units loses ln(2)/2 of it per unit
+	glass := Glass(0).Attenuate(rgb.Energy{0.5, 1, 1}, 2)
+	if a := glass.Absorb(); math.Abs(a.X-math.Ln2/2) > 1e-12 || a.Y != 0 || a.Z != 0 {
+		t.Error("Expected absorption of", math.Ln2/2, "red per unit, got", a)
+	}
+	glass.AttenuationDistance = 1
+	if a := glass.Absorb(); math.Abs(a.X-math.Ln2) > 1e-12 {
+		t.Error("Expected a changed AttenuationDistance to take effect, got", a)
+	}
+	if a := Glass(0).Absorb(); !a.Zero() {
+		t.Error("Expected clear glass to absorb nothing, got", a)
+	}
+}
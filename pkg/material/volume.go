@@ -0,0 +1,33 @@
+package material
+
+import (
+	"math/rand"
+
+	"github.com/hunterloftis/pbr/pkg/bsdf"
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/render"
+	"github.com/hunterloftis/pbr/pkg/rgb"
+	"github.com/hunterloftis/pbr/pkg/texture"
+)
+
+// Volume is an invisible boundary around a Medium, such as a cloud or a column of smoke.
+// Like Subsurface, it should cover closed meshes.
+type Volume struct {
+	Medium render.Medium
+}
+
+func NewVolume(m render.Medium) *Volume {
+	return &Volume{Medium: m}
+}
+
+func (v *Volume) At(c texture.Coord, in, norm geom.Dir, rnd *rand.Rand) (geom.Dir, render.BSDF) {
+	return geom.Up, bsdf.Boundary{BSDF: bsdf.Ignore{}, Medium: v.Medium}
+}
+
+func (v *Volume) Light() rgb.Energy {
+	return rgb.Black
+}
+
+func (v *Volume) Absorb() rgb.Energy {
+	return rgb.Black
+}
@@ -0,0 +1,152 @@
+package medium
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/rgb"
+)
+
+// Grid is a heterogeneous medium, like smoke or a cloud, with its density in voxels spanning Bounds.
+// Density is trilinearly interpolated between voxel centers and is zero outside of Bounds.
+// At a density of 1, light travels MeanFreePath between interactions on average, in every channel;
+// Albedo and Anisotropy are as in Homogeneous.
+type Grid struct {
+	Bounds       *geom.Bounds
+	Width        int
+	Height       int
+	Depth        int
+	Density      []float64 // Width * Height * Depth voxels, x varying fastest, then y
+	Albedo       rgb.Energy
+	MeanFreePath float64
+	Anisotropy   float64
+	peak         float64
+}
+
+func NewGrid(bounds *geom.Bounds, width, height, depth int, density []float64) *Grid {
+	g := &Grid{
+		Bounds:       bounds,
+		Width:        width,
+		Height:       height,
+		Depth:        depth,
+		Density:      density,
+		Albedo:       rgb.White,
+		MeanFreePath: 1,
+	}
+	for _, d := range density {
+		g.peak = math.Max(g.peak, d)
+	}
+	return g
+}
+
+// Sample finds the first real interaction among candidates sampled at the peak density (delta tracking).
+// https://cs.dartmouth.edu/wjarosz/publications/novak18monte.html
+func (g *Grid) Sample(ray *geom.Ray, max float64, rnd *rand.Rand) (float64, rgb.Energy, bool) {
+	majorant := g.majorant()
+	near, far, ok := g.span(ray, max)
+	if !ok || majorant == 0 {
+		return max, rgb.White, false
+	}
+	for t := near; ; {
+		t -= math.Log(1-rnd.Float64()) / majorant
+		if t >= far {
+			return max, rgb.White, false
+		}
+		if rnd.Float64()*majorant < g.extinction(ray.Moved(t)) {
+			return t, g.Albedo, true
+		}
+	}
+}
+
+// Transmittance multiplies the fraction of null interactions at candidates sampled at the peak density (ratio tracking).
+func (g *Grid) Transmittance(ray *geom.Ray, dist float64, rnd *rand.Rand) rgb.Energy {
+	majorant := g.majorant()
+	near, far, ok := g.span(ray, dist)
+	if !ok || majorant == 0 {
+		return rgb.White
+	}
+	tr := 1.0
+	for t := near; ; {
+		t -= math.Log(1-rnd.Float64()) / majorant
+		if t >= far {
+			return rgb.Energy{tr, tr, tr}
+		}
+		tr *= 1 - g.extinction(ray.Moved(t))/majorant
+	}
+}
+
+func (g *Grid) Scatter(dir geom.Dir, rnd *rand.Rand) geom.Dir {
+	return henyeyGreenstein(dir, g.Anisotropy, rnd)
+}
+
+func (g *Grid) Phase(dir, scattered geom.Dir) float64 {
+	return phaseHG(dir.Dot(scattered), g.Anisotropy)
+}
+
+// majorant returns the greatest extinction anywhere in the grid.
+func (g *Grid) majorant() float64 {
+	if g.MeanFreePath <= 0 {
+		return 0
+	}
+	return g.peak / g.MeanFreePath
+}
+
+// span returns the distances along ray, up to max, between which it is within Bounds.
+func (g *Grid) span(ray *geom.Ray, max float64) (near, far float64, ok bool) {
+	hit, near, far := g.Bounds.Check(ray)
+	far = math.Min(far, max)
+	return math.Max(0, near), far, hit && near < far
+}
+
+func (g *Grid) extinction(pt geom.Vec) float64 {
+	return g.density(pt) / g.MeanFreePath
+}
+
+// density returns the trilinear interpolation of the voxels around pt.
+func (g *Grid) density(pt geom.Vec) float64 {
+	size := g.Bounds.Max.Minus(g.Bounds.Min)
+	rel := pt.Minus(g.Bounds.Min)
+	x := rel.X/size.X*float64(g.Width) - 0.5
+	y := rel.Y/size.Y*float64(g.Height) - 0.5
+	z := rel.Z/size.Z*float64(g.Depth) - 0.5
+	x0, y0, z0 := math.Floor(x), math.Floor(y), math.Floor(z)
+	fx, fy, fz := x-x0, y-y0, z-z0
+	ix, iy, iz := int(x0), int(y0), int(z0)
+	sum := 0.0
+	for dz := 0; dz <= 1; dz++ {
+		for dy := 0; dy <= 1; dy++ {
+			for dx := 0; dx <= 1; dx++ {
+				w := weight(fx, dx) * weight(fy, dy) * weight(fz, dz)
+				sum += w * g.voxel(ix+dx, iy+dy, iz+dz)
+			}
+		}
+	}
+	return sum
+}
+
+// voxel returns the density of the voxel at x, y, z, clamping to the edges of the grid.
+func (g *Grid) voxel(x, y, z int) float64 {
+	x = clamp(x, g.Width-1)
+	y = clamp(y, g.Height-1)
+	z = clamp(z, g.Depth-1)
+	return g.Density[(z*g.Height+y)*g.Width+x]
+}
+
+func clamp(i, max int) int {
+	if i < 0 {
+		return 0
+	}
+	if i > max {
+		return max
+	}
+	return i
+}
+
+// weight returns the trilinear weight of the lower (d = 0) or upper (d = 1) neighbor at fraction f.
+func weight(f float64, d int) float64 {
+	if d == 0 {
+		return 1 - f
+	}
+	return f
+}
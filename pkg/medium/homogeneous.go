@@ -0,0 +1,96 @@
+package medium
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/rgb"
+)
+
+// Homogeneous is a medium with the same density everywhere.
+// Albedo is the fraction of light that scatters, rather than being absorbed, at each interaction,
+// and MeanFreePath is the average distance light travels between interactions, in each channel.
+// Anisotropy, from -1 to 1, is the mean cosine of the angle through which light scatters:
+// negative values scatter back toward the light, and positive values forward.
+type Homogeneous struct {
+	Albedo       rgb.Energy
+	MeanFreePath rgb.Energy
+	Anisotropy   float64
+}
+
+// NewHomogeneous returns the medium with scattering and absorption coefficients per unit distance.
+func NewHomogeneous(scattering, absorption rgb.Energy, anisotropy float64) *Homogeneous {
+	extinction := scattering.Plus(absorption)
+	ratio := func(a, b float64) float64 {
+		if b <= 0 {
+			return 0
+		}
+		return a / b
+	}
+	return &Homogeneous{
+		Albedo:       rgb.Energy{ratio(scattering.X, extinction.X), ratio(scattering.Y, extinction.Y), ratio(scattering.Z, extinction.Z)},
+		MeanFreePath: rgb.Energy{ratio(1, extinction.X), ratio(1, extinction.Y), ratio(1, extinction.Z)},
+		Anisotropy:   anisotropy,
+	}
+}
+
+// Sample picks a channel at random and samples an exponential distance by its extinction,
+// weighting the path by the average pdf of all three channels (spectral MIS).
+// https://cg.ivd.kit.edu/publications/2017/volume_rendering/volume_rendering.pdf
+func (h Homogeneous) Sample(ray *geom.Ray, max float64, rnd *rand.Rand) (float64, rgb.Energy, bool) {
+	sigma := extinction(h.MeanFreePath)
+	dist := -math.Log(1-rnd.Float64()) / channel(sigma, rnd.Intn(3))
+	if dist >= max {
+		tr := transmittance(sigma, max)
+		return max, tr.Scaled(1 / tr.Mean()), false
+	}
+	tr := transmittance(sigma, dist)
+	pdf := sigma.Times(tr).Mean()
+	return dist, tr.Times(sigma).Times(h.Albedo).Scaled(1 / pdf), true
+}
+
+func (h Homogeneous) Transmittance(ray *geom.Ray, dist float64, rnd *rand.Rand) rgb.Energy {
+	return transmittance(extinction(h.MeanFreePath), dist)
+}
+
+func (h Homogeneous) Scatter(dir geom.Dir, rnd *rand.Rand) geom.Dir {
+	return henyeyGreenstein(dir, h.Anisotropy, rnd)
+}
+
+func (h Homogeneous) Phase(dir, scattered geom.Dir) float64 {
+	return phaseHG(dir.Dot(scattered), h.Anisotropy)
+}
+
+// extinction returns the probability per unit distance that light interacts with a medium, in each channel.
+// A zero mean free path makes a channel clear.
+func extinction(mfp rgb.Energy) rgb.Energy {
+	inv := func(d float64) float64 {
+		if d <= 0 {
+			return 0
+		}
+		return 1 / d
+	}
+	return rgb.Energy{inv(mfp.X), inv(mfp.Y), inv(mfp.Z)}
+}
+
+// transmittance returns the fraction of light that travels dist through extinction sigma without interacting.
+func transmittance(sigma rgb.Energy, dist float64) rgb.Energy {
+	tr := func(s float64) float64 {
+		if s == 0 {
+			return 1
+		}
+		return math.Exp(-s * dist)
+	}
+	return rgb.Energy{tr(sigma.X), tr(sigma.Y), tr(sigma.Z)}
+}
+
+func channel(e rgb.Energy, i int) float64 {
+	switch i {
+	case 0:
+		return e.X
+	case 1:
+		return e.Y
+	}
+	return e.Z
+}
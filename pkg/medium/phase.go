@@ -0,0 +1,33 @@
+package medium
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+)
+
+// phaseHG returns the density of the Henyey-Greenstein phase function with mean cosine g,
+// per steradian, at an angle with cosine cos to the direction of travel.
+func phaseHG(cos, g float64) float64 {
+	d := 1 + g*g - 2*g*cos
+	return (1 - g*g) / (4 * math.Pi * d * math.Sqrt(d))
+}
+
+// henyeyGreenstein samples a direction scattered from dir by the Henyey-Greenstein phase function with mean cosine g.
+// https://www.pbr-book.org/3ed-2018/Light_Transport_II_Volume_Rendering/Sampling_Volume_Scattering#SamplingPhaseFunctions
+func henyeyGreenstein(dir geom.Dir, g float64, rnd *rand.Rand) geom.Dir {
+	u := rnd.Float64()
+	cos := 1 - 2*u
+	if math.Abs(g) > 1e-3 {
+		s := (1 - g*g) / (1 - g + 2*g*u)
+		cos = (1 + g*g - s*s) / (2 * g)
+	}
+	if dir.Y < 0 {
+		dir, cos = dir.Inv(), -cos // geom.Tangent can't turn Up to face straight down
+	}
+	sin := math.Sqrt(math.Max(0, 1-cos*cos))
+	phi := 2 * math.Pi * rnd.Float64()
+	_, from := geom.Tangent(dir)
+	return from.MultDir(geom.Dir{sin * math.Cos(phi), cos, sin * math.Sin(phi)})
+}
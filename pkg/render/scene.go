@@ -4,6 +4,7 @@ type Scene struct {
 	Camera  Camera
 	Env     Environment
 	Surface Surface
+	Medium  Medium // that fills the scene's bounds outside of surfaces, like fog, or nil for none
 }
 
 func NewScene(c Camera, s Surface, e Environment) *Scene {
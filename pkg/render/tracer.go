@@ -12,6 +12,8 @@ import (
 const (
 	maxWeight = 10
 	maxEnergy = 2000
+	maxWalk   = 512 // scattering events within media before a path is abandoned; they don't count toward its depth
+	maxCross  = 64  // invisible boundaries that a ray passes through before it's abandoned; they don't count toward its depth
 )
 
 var (
@@ -22,6 +24,11 @@ type Camera interface {
 	Ray(x, y, width, height float64, rnd *rand.Rand) *geom.Ray
 }
 
+// Spreader is a Camera that knows the angle between the rays of neighboring pixels.
+type Spreader interface {
+	PixelAngle(width, height float64) float64
+}
+
 type Environment interface {
 	At(geom.Dir) rgb.Energy
 }
@@ -32,11 +39,18 @@ type Surface interface {
 	Bounds() *geom.Bounds
 }
 
+// Object is a point of intersection with a Surface.
+// At is given the width of the ray's footprint at pt, which it may use to filter textures.
 type Object interface {
-	At(pt geom.Vec, dir geom.Dir, rnd *rand.Rand) (normal geom.Dir, bsdf BSDF)
+	At(pt geom.Vec, dir geom.Dir, width float64, rnd *rand.Rand) (normal geom.Dir, bsdf BSDF)
 	Bounds() *geom.Bounds
-	Light() rgb.Energy    // TODO: rename to Emit()? Lumens()? <-- would need to actually be lumens in that case
-	Transmit() rgb.Energy // TODO: rename to Absorb() and precompute logarithms?
+	Light() rgb.Energy  // TODO: rename to Emit()? Lumens()? <-- would need to actually be lumens in that case
+	Absorb() rgb.Energy // coefficients of absorption per unit distance inside the Object, if it transmits light
+}
+
+// Emitter is an Object whose Light varies across its surface.
+type Emitter interface {
+	LightAt(pt geom.Vec) rgb.Energy
 }
 
 type BSDF interface {
@@ -44,6 +58,58 @@ type BSDF interface {
 	Eval(wi, wo geom.Dir) rgb.Energy
 }
 
+// Medium is a volume that absorbs and scatters light between surfaces, like fog or the inside of marble.
+type Medium interface {
+	// Sample returns how far along ray, up to max, light scatters,
+	// with the throughput of the path to that point divided by its probability.
+	// If the light travels max without scattering, it returns max and false.
+	Sample(ray *geom.Ray, max float64, rnd *rand.Rand) (dist float64, weight rgb.Energy, scattered bool)
+	// Transmittance returns the fraction of light that travels dist along ray without scattering or being absorbed.
+	Transmittance(ray *geom.Ray, dist float64, rnd *rand.Rand) rgb.Energy
+	// Scatter samples a new direction for light traveling in dir, in proportion to the medium's phase function.
+	Scatter(dir geom.Dir, rnd *rand.Rand) geom.Dir
+	// Phase returns the density, per steradian, of light traveling in dir scattering into the direction scattered.
+	Phase(dir, scattered geom.Dir) float64
+}
+
+// Boundary is a BSDF on the surface of a volume, like a glass, a liquid within it, or the skin around marble.
+// Light it transmits inward travels through the Interior, if it isn't nil, until it leaves through another Boundary.
+// Where volumes overlap, the one with the highest Priority fills the overlap
+// and the boundaries of the others are ignored within it.
+type Boundary interface {
+	Interior() Medium
+	Priority() int
+	// IOR returns the refractive index inside the volume.
+	IOR() float64
+	// Outside returns the BSDF of the boundary with a refractive index of ior beyond it.
+	Outside(ior float64) BSDF
+}
+
+// Delta is a BSDF that scatters into discrete directions, like a perfect mirror.
+// Its pdf is a probability rather than a density and Eval is zero in every other direction,
+// so lights are never sampled directly from it.
+type Delta interface {
+	Delta() bool
+}
+
+// Invisible is a BSDF that passes light straight through, like the boundary of a cloud.
+// Shadow rays pass through it rather than being blocked.
+type Invisible interface {
+	Invisible() bool
+}
+
+// isInvisible returns whether b passes light straight through.
+func isInvisible(b BSDF) bool {
+	i, ok := b.(Invisible)
+	return ok && i.Invisible()
+}
+
+// isDelta returns whether b scatters into discrete directions.
+func isDelta(b BSDF) bool {
+	d, ok := b.(Delta)
+	return ok && d.Delta()
+}
+
 type tracer struct {
 	scene  *Scene
 	out    chan *Sample
@@ -52,9 +118,14 @@ type tracer struct {
 	local  *Sample
 	bounce int
 	direct bool
+	spread float64
 }
 
 func newTracer(s *Scene, o chan *Sample, w, h, bounce int, direct bool) *tracer {
+	spread := 0.0
+	if s, ok := s.Camera.(Spreader); ok {
+		spread = s.PixelAngle(float64(w), float64(h))
+	}
 	return &tracer{
 		scene:  s,
 		out:    o,
@@ -62,6 +133,7 @@ func newTracer(s *Scene, o chan *Sample, w, h, bounce int, direct bool) *tracer
 		local:  NewSample(w, h),
 		bounce: bounce,
 		direct: direct,
+		spread: spread,
 	}
 }
 
@@ -98,41 +170,103 @@ func (t *tracer) process() {
 func (t *tracer) trace(ray *geom.Ray, depth int) rgb.Energy {
 	energy := rgb.Black
 	signal := rgb.White
+	length := 0.0
+	medium := t.scene.Medium // that the ray is traveling through, or nil for none
+	var inside volumes
+	walk := 0
+	crossed := 0
+	sampled := false // whether a light the ray reaches was already sampled directly from a medium
 
 	for d := 0; d < depth; d++ {
 		obj, dist := t.scene.Surface.Intersect(ray, infinity)
 
+		// Random walk through the medium until the ray reaches a surface.
+		// https://www.pbr-book.org/4ed/Light_Transport_II_Volume_Rendering/Volume_Scattering_Integrators
+		if medium != nil {
+			if walk >= maxWalk {
+				break
+			}
+			max := dist
+			if obj == nil {
+				max = t.exit(ray) // rays that miss every surface leave through the scene's bounds
+			}
+			travel, weight, scattered := medium.Sample(ray, max, t.rnd)
+			signal = signal.Times(weight)
+			if scattered {
+				walk++
+				d--
+				length += travel
+				pt := ray.Moved(travel)
+				if t.direct {
+					energy = energy.Plus(t.scatterLight(pt, ray.Dir, inside).Times(signal))
+					sampled = true
+				}
+				signal = signal.RandomGain(t.rnd)
+				if signal.Zero() {
+					break
+				}
+				ray = geom.NewRay(pt, medium.Scatter(ray.Dir, t.rnd))
+				continue
+			}
+		}
+
 		if obj == nil {
 			env := t.scene.Env.At(ray.Dir).Times(signal)
 			energy = energy.Plus(env)
 			break
 		}
+		pt := ray.Moved(dist)
+		length += dist
 		if l := obj.Light(); !l.Zero() {
-			energy = energy.Plus(l.Times(signal))
+			if !sampled {
+				energy = energy.Plus(lightAt(obj, pt).Times(signal))
+			}
 			break
 		}
 
-		pt := ray.Moved(dist)
-		normal, bsdf := obj.At(pt, ray.Dir, t.rnd)
+		// Estimate the ray's footprint from the total path length.
+		// https://www.pbr-book.org/4ed/Textures_and_Materials/Texture_Sampling_and_Antialiasing
+		normal, bsdf := obj.At(pt, ray.Dir, t.spread*length, t.rnd)
+		toTan, fromTan := geom.Tangent(normal)
+		wo := toTan.MultDir(ray.Dir.Inv())
+		entering := wo.Y > 0
+		bo, bounded := bsdf.(Boundary)
 
-		if !ray.Dir.Enters(normal) {
-			t := obj.Transmit()
-			// if t.Zero() {	// TODO: should this be removed again now that the triangle distance bug is fixed?
-			// 	ray = geom.NewRay(pt, ray.Dir)
-			// 	continue
-			// }
-			transmittance := beers(dist, t)
-			signal = signal.Times(transmittance)
+		if i, ok := inside.top(); ok {
+			signal = signal.Times(beers(dist, inside[i].absorb))
+		} else if !bounded && !entering {
+			signal = signal.Times(beers(dist, obj.Absorb()))
 		}
 
-		toTan, fromTan := geom.Tangent(normal)
-		wo := toTan.MultDir(ray.Dir.Inv())
+		// Pass straight through invisible boundaries and those of volumes within others of higher priority,
+		// and refract through the rest by the ratio of the refractive indices on either side.
+		var vol volume
+		if bounded {
+			vol = inner(bo, obj)
+		}
+		if isInvisible(bsdf) || (bounded && !inside.visible(vol)) {
+			if crossed >= maxCross {
+				break
+			}
+			crossed++
+			d--
+			if bounded {
+				inside = inside.cross(vol, entering)
+				medium = inside.medium(t.scene.Medium)
+			}
+			ray = geom.NewRay(pt, ray.Dir)
+			continue
+		}
+		sampled = false
+		if bounded {
+			bsdf = bo.Outside(inside.outside(vol, entering))
+		}
 		indirect := 1.0
 
 		wi, pdf, shadow := bsdf.Sample(wo, t.rnd)
 
-		if t.direct && shadow {
-			dir, light, coverage := t.shadow(pt, normal)
+		if t.direct && shadow && !isDelta(bsdf) {
+			dir, light, coverage := t.shadow(pt, normal, inside)
 			wiDirect := toTan.MultDir(dir)
 			if coverage > 0 {
 				reflectance := bsdf.Eval(wiDirect, wo).Scaled(coverage)
@@ -142,8 +276,19 @@ func (t *tracer) trace(ray *geom.Ray, depth int) rgb.Energy {
 			}
 		}
 
-		weight := math.Min(maxWeight, indirect/pdf)
-		reflectance := bsdf.Eval(wi, wo).Scaled(weight)
+		if bounded && (wi.Y > 0) != entering {
+			inside = inside.cross(vol, entering)
+			medium = inside.medium(t.scene.Medium)
+		}
+
+		if pdf <= 0 {
+			break
+		}
+		// Limit the gain of improbable samples, which would otherwise be fireflies.
+		reflectance := bsdf.Eval(wi, wo).Scaled(indirect / pdf)
+		if gain := reflectance.Max(); gain > maxWeight {
+			reflectance = reflectance.Scaled(maxWeight / gain)
+		}
 		bounce := fromTan.MultDir(wi)
 		signal = signal.Times(reflectance).RandomGain(t.rnd)
 
@@ -158,7 +303,9 @@ func (t *tracer) trace(ray *geom.Ray, depth int) rgb.Energy {
 }
 
 // https://blog.yiningkarlli.com/2013/04/importance-sampled-direct-lighting.html
-func (t *tracer) shadow(pt geom.Vec, normal geom.Dir) (wi geom.Dir, energy rgb.Energy, coverage float64) {
+// The shadow ray starts within inside and passes through invisible boundaries, and those hidden within volumes of higher priority,
+// so the light is only attenuated by the media it crosses on its way to pt.
+func (t *tracer) shadow(pt geom.Vec, normal geom.Dir, inside volumes) (wi geom.Dir, energy rgb.Energy, coverage float64) {
 	lights := t.scene.Surface.Lights()
 	if len(lights) < 1 {
 		return geom.Up, rgb.Black, 0
@@ -171,30 +318,83 @@ func (t *tracer) shadow(pt geom.Vec, normal geom.Dir) (wi geom.Dir, energy rgb.E
 		return geom.Up, rgb.Black, 0
 	}
 
-	obj, _ := t.scene.Surface.Intersect(ray, infinity)
-	if obj == nil {
-		return geom.Up, rgb.Black, 0
+	signal := rgb.White
+	for i := 0; i < maxCross; i++ {
+		obj, dist := t.scene.Surface.Intersect(ray, infinity)
+		if obj == nil {
+			return geom.Up, rgb.Black, 0
+		}
+		if medium := inside.medium(t.scene.Medium); medium != nil {
+			signal = signal.Times(medium.Transmittance(ray, dist, t.rnd))
+		}
+		if top, ok := inside.top(); ok {
+			signal = signal.Times(beers(dist, inside[top].absorb))
+		}
+		pt := ray.Moved(dist)
+		if !obj.Light().Zero() {
+			return ray.Dir, lightAt(obj, pt).Times(signal), coverage
+		}
+		normal, bsdf := obj.At(pt, ray.Dir, 0, t.rnd)
+		bo, bounded := bsdf.(Boundary)
+		if !bounded {
+			break
+		}
+		vol := inner(bo, obj)
+		if !isInvisible(bsdf) && inside.visible(vol) {
+			break
+		}
+		inside = inside.cross(vol, ray.Dir.Dot(normal) < 0)
+		ray = geom.NewRay(pt, ray.Dir)
 	}
+	return ray.Dir, rgb.Black, coverage
+}
+
+// inner returns the volume inside obj's Boundary bo.
+func inner(bo Boundary, obj Object) volume {
+	return volume{priority: bo.Priority(), ior: bo.IOR(), medium: bo.Interior(), absorb: obj.Absorb()}
+}
 
-	return ray.Dir, obj.Light(), coverage
+// scatterLight estimates the light from a randomly chosen light that scatters toward -dir at pt within medium.
+// Unlike at surfaces, it accounts for all of the light arriving from the chosen light's solid angle,
+// so the path that continues from pt must not count that light again.
+func (t *tracer) scatterLight(pt geom.Vec, dir geom.Dir, inside volumes) rgb.Energy {
+	medium := inside.medium(t.scene.Medium)
+	wi, light, coverage := t.shadow(pt, dir, inside)
+	if coverage <= 0 {
+		return rgb.Black
+	}
+	lights := float64(len(t.scene.Surface.Lights()))
+	solidAngle := 2 * math.Pi * coverage
+	return light.Scaled(medium.Phase(dir, wi) * solidAngle * lights)
+}
+
+// exit returns the distance along ray to the edge of the scene's bounds.
+func (t *tracer) exit(ray *geom.Ray) float64 {
+	ok, _, far := t.scene.Surface.Bounds().Check(ray)
+	if !ok {
+		return 0
+	}
+	return math.Max(0, far)
+}
+
+// lightAt returns the light emitted by obj at pt.
+func lightAt(obj Object, pt geom.Vec) rgb.Energy {
+	if e, ok := obj.(Emitter); ok {
+		return e.LightAt(pt)
+	}
+	return obj.Light()
 }
 
-// Beer's Law.
-// http://www.epolin.com/converting-absorbance-transmittance
-// https://en.wikipedia.org/wiki/Optical_depth
-func beers(dist float64, transmit rgb.Energy) rgb.Energy {
-	// Avoid edge cases
-	if dist == 0 || transmit.Zero() {
+// beers returns the fraction of light that remains after traveling dist through a volume
+// with coefficients of absorption absorb, by Beer's Law.
+// https://en.wikipedia.org/wiki/Beer%E2%80%93Lambert_law
+func beers(dist float64, absorb rgb.Energy) rgb.Energy {
+	if absorb.Zero() {
 		return rgb.White
 	}
-	// TODO: precompute this on materials, use absorption instead of transmission?
-	absorb := rgb.Energy{
-		X: 2 - math.Log10(transmit.X*100),
-		Y: 2 - math.Log10(transmit.Y*100),
-		Z: 2 - math.Log10(transmit.Z*100),
+	return rgb.Energy{
+		X: math.Exp(-absorb.X * dist),
+		Y: math.Exp(-absorb.Y * dist),
+		Z: math.Exp(-absorb.Z * dist),
 	}
-	r := math.Exp(-absorb.X * dist)
-	g := math.Exp(-absorb.Y * dist)
-	b := math.Exp(-absorb.Z * dist)
-	return rgb.Energy{r, g, b}
 }
@@ -0,0 +1,87 @@
+package render
+
+import "github.com/hunterloftis/pbr/pkg/rgb"
+
+// volume is the inside of a Boundary that a path has entered.
+type volume struct {
+	priority int
+	ior      float64
+	medium   Medium
+	absorb   rgb.Energy
+}
+
+// same returns whether v and o are the insides of the same kind of Boundary.
+// Media aren't compared, since not every Medium is comparable.
+func (v volume) same(o volume) bool {
+	return v.priority == o.priority && v.ior == o.ior && v.absorb == o.absorb
+}
+
+// volumes are the insides of the Boundaries that a path is within, in the order it entered them.
+// Boundaries within a volume of higher priority are false intersections, which the path passes through,
+// so overlapping and coincident surfaces (like liquid modeled to overlap its glass) refract correctly.
+// https://www.tandfonline.com/doi/abs/10.1080/10867651.2002.10487555
+type volumes []volume
+
+// top returns the index of the volume that fills the path's location:
+// the highest priority, or the most recently entered among equals.
+// It returns false if the path is outside of every volume.
+func (vs volumes) top() (int, bool) {
+	top := -1
+	for i, v := range vs {
+		if top < 0 || v.priority >= vs[top].priority {
+			top = i
+		}
+	}
+	return top, top >= 0
+}
+
+// visible returns whether the boundary of v, which a path is entering or leaving, is a true intersection.
+// It isn't if a volume of higher priority fills the path's location.
+func (vs volumes) visible(v volume) bool {
+	i, ok := vs.top()
+	return !ok || v.priority >= vs[i].priority
+}
+
+// cross returns the volumes that a path is within after entering or leaving v.
+// A path leaving a volume it never entered the same way (like one whose textured absorption differs across its surface)
+// leaves the most recent volume of the same priority instead, so its stack doesn't grow out of step with its surfaces.
+func (vs volumes) cross(v volume, entering bool) volumes {
+	if entering {
+		return append(vs, v)
+	}
+	for i := len(vs) - 1; i >= 0; i-- {
+		if vs[i].same(v) {
+			return vs.without(i)
+		}
+	}
+	for i := len(vs) - 1; i >= 0; i-- {
+		if vs[i].priority == v.priority {
+			return vs.without(i)
+		}
+	}
+	return vs
+}
+
+// without returns a copy of vs without the volume at index i.
+func (vs volumes) without(i int) volumes {
+	return append(vs[:i:i], vs[i+1:]...)
+}
+
+// outside returns the refractive index beyond the boundary of v, which is 1 if no other volume fills it.
+func (vs volumes) outside(v volume, entering bool) float64 {
+	if !entering {
+		vs = vs.cross(v, false)
+	}
+	if i, ok := vs.top(); ok {
+		return vs[i].ior
+	}
+	return 1
+}
+
+// medium returns the Medium that fills the path's location, which is fill outside of every volume.
+func (vs volumes) medium(fill Medium) Medium {
+	if i, ok := vs.top(); ok {
+		return vs[i].medium
+	}
+	return fill
+}
@@ -0,0 +1,170 @@
+// Package sdf implements signed distance functions and combinators for use with surface.SDF.
+// http://iquilezles.org/www/articles/distfunctions/distfunctions.htm
+package sdf
+
+import (
+	"math"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+)
+
+// Func returns the signed distance from a point to a surface:
+// negative inside, positive outside, and zero on the surface.
+type Func func(p geom.Vec) float64
+
+// Sphere returns the distance function of a sphere at the origin.
+func Sphere(radius float64) Func {
+	return func(p geom.Vec) float64 {
+		return p.Len() - radius
+	}
+}
+
+// Box returns the distance function of a box at the origin with the given dimensions.
+func Box(size geom.Vec) Func {
+	half := size.Scaled(0.5)
+	return func(p geom.Vec) float64 {
+		q := p.Abs().Minus(half)
+		outside := q.Max(geom.Vec{}).Len()
+		inside := math.Min(q.Greatest(), 0)
+		return outside + inside
+	}
+}
+
+// RoundBox returns the distance function of a box with edges rounded by radius.
+// The overall dimensions of the box are unchanged by the rounding.
+func RoundBox(size geom.Vec, radius float64) Func {
+	inner := size.Minus(geom.Vec{radius, radius, radius}.Scaled(2))
+	return Round(radius, Box(inner.Max(geom.Vec{})))
+}
+
+// Torus returns the distance function of a torus around the Y axis.
+func Torus(major, minor float64) Func {
+	return func(p geom.Vec) float64 {
+		x := math.Hypot(p.X, p.Z) - major
+		return math.Hypot(x, p.Y) - minor
+	}
+}
+
+// Cylinder returns the distance function of a capped cylinder around the Y axis.
+func Cylinder(radius, height float64) Func {
+	return func(p geom.Vec) float64 {
+		dx := math.Hypot(p.X, p.Z) - radius
+		dy := math.Abs(p.Y) - height/2
+		outside := math.Hypot(math.Max(dx, 0), math.Max(dy, 0))
+		inside := math.Min(math.Max(dx, dy), 0)
+		return outside + inside
+	}
+}
+
+// Capsule returns the distance function of a line segment from a to b with the given radius.
+func Capsule(a, b geom.Vec, radius float64) Func {
+	ba := b.Minus(a)
+	l2 := ba.Dot(ba)
+	return func(p geom.Vec) float64 {
+		pa := p.Minus(a)
+		h := 0.0
+		if l2 > 0 {
+			h = math.Max(0, math.Min(1, pa.Dot(ba)/l2))
+		}
+		return pa.Minus(ba.Scaled(h)).Len() - radius
+	}
+}
+
+// Union joins surfaces together.
+func Union(fs ...Func) Func {
+	return func(p geom.Vec) float64 {
+		d := math.Inf(1)
+		for _, f := range fs {
+			d = math.Min(d, f(p))
+		}
+		return d
+	}
+}
+
+// Intersection keeps only the space shared by all surfaces.
+func Intersection(fs ...Func) Func {
+	return func(p geom.Vec) float64 {
+		d := math.Inf(-1)
+		for _, f := range fs {
+			d = math.Max(d, f(p))
+		}
+		return d
+	}
+}
+
+// Subtract carves surface b out of surface a.
+func Subtract(a, b Func) Func {
+	return func(p geom.Vec) float64 {
+		return math.Max(a(p), -b(p))
+	}
+}
+
+// SmoothUnion joins surfaces a and b, blending them together within distance k.
+// http://iquilezles.org/www/articles/smin/smin.htm
+func SmoothUnion(k float64, a, b Func) Func {
+	return func(p geom.Vec) float64 {
+		da, db := a(p), b(p)
+		h := math.Max(0, math.Min(1, 0.5+0.5*(db-da)/k))
+		return lerp(db, da, h) - k*h*(1-h)
+	}
+}
+
+// SmoothSubtract carves surface b out of surface a, blending the cut within distance k.
+func SmoothSubtract(k float64, a, b Func) Func {
+	return func(p geom.Vec) float64 {
+		da, db := a(p), b(p)
+		h := math.Max(0, math.Min(1, 0.5-0.5*(da+db)/k))
+		return lerp(da, -db, h) + k*h*(1-h)
+	}
+}
+
+// Round inflates a surface by radius, rounding its edges.
+func Round(radius float64, f Func) Func {
+	return func(p geom.Vec) float64 {
+		return f(p) - radius
+	}
+}
+
+// Shift moves a surface by v.
+func Shift(v geom.Vec, f Func) Func {
+	return func(p geom.Vec) float64 {
+		return f(p.Minus(v))
+	}
+}
+
+// Repeat tiles a surface infinitely, with the given spacing on each axis.
+// An axis with a spacing of zero is not repeated.
+func Repeat(spacing geom.Vec, f Func) Func {
+	return func(p geom.Vec) float64 {
+		q := geom.Vec{
+			X: repeat(p.X, spacing.X),
+			Y: repeat(p.Y, spacing.Y),
+			Z: repeat(p.Z, spacing.Z),
+		}
+		return f(q)
+	}
+}
+
+// Twist rotates a surface around the Y axis by k radians per unit of height.
+// Twisting stretches space, so distances are conservatively scaled down
+// by the local stretch factor to keep sphere tracing from overstepping.
+func Twist(k float64, f Func) Func {
+	return func(p geom.Vec) float64 {
+		a := k * p.Y
+		c, s := math.Cos(a), math.Sin(a)
+		q := geom.Vec{c*p.X - s*p.Z, p.Y, s*p.X + c*p.Z}
+		stretch := math.Sqrt(1 + k*k*(p.X*p.X+p.Z*p.Z))
+		return f(q) / stretch
+	}
+}
+
+func repeat(x, spacing float64) float64 {
+	if spacing == 0 {
+		return x
+	}
+	return x - spacing*math.Floor(x/spacing+0.5)
+}
+
+func lerp(a, b, n float64) float64 {
+	return a*(1-n) + b*n
+}
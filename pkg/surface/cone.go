@@ -0,0 +1,137 @@
+package surface
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/render"
+	"github.com/hunterloftis/pbr/pkg/rgb"
+)
+
+// Cone describes a capped cone around the Y axis
+// with a base of diameter 1 at y = -0.5 and its apex at y = 0.5.
+type Cone struct {
+	mtx    *geom.Mtx
+	mat    Material
+	bounds *geom.Bounds
+}
+
+// UnitCone returns a pointer to a new 1x1x1 Cone Surface with material and optional transforms.
+func UnitCone(m ...Material) *Cone {
+	c := &Cone{
+		mtx: geom.Identity(),
+		mat: &DefaultMaterial{},
+	}
+	if len(m) > 0 {
+		c.mat = m[0]
+	}
+	return c.transform(geom.Identity())
+}
+
+func (c *Cone) Intersect(ray *geom.Ray, max float64) (obj render.Object, dist float64) {
+	if ok, near, _ := c.bounds.Check(ray); !ok || near >= max {
+		return nil, 0
+	}
+	r := c.mtx.Inverse().MultRay(ray)
+	if dist, ok := nearest(c.mtx, r.Dir, max, c.crossings(r)...); ok {
+		return c, dist
+	}
+	return nil, 0
+}
+
+// Spans returns the span of the ray within the Cone.
+func (c *Cone) Spans(ray *geom.Ray) []Span {
+	r := c.mtx.Inverse().MultRay(ray)
+	return convexSpans(c, c.mtx, r.Dir, c.crossings(r))
+}
+
+// crossings solves x^2 + z^2 = ((0.5 - y) / 2)^2 for the side and adds the base cap.
+func (c *Cone) crossings(r *geom.Ray) []float64 {
+	ts := make([]float64, 0, 3)
+	o, d := r.Origin, r.Dir
+	k := 0.5 - o.Y
+	a := d.X*d.X + d.Z*d.Z - d.Y*d.Y/4
+	b := 2*(o.X*d.X+o.Z*d.Z) + k*d.Y/2
+	cc := o.X*o.X + o.Z*o.Z - k*k/4
+	for _, t := range solveQuadratic(a, b, cc) {
+		if y := o.Y + t*d.Y; y >= -0.5 && y <= 0.5 {
+			ts = append(ts, t)
+		}
+	}
+	return append(ts, capHits(r, -0.5, 0.5)...)
+}
+
+// At returns the normal geom.Vec at this point on the Surface
+func (c *Cone) At(pt geom.Vec, in geom.Dir, width float64, rnd *rand.Rand) (normal geom.Dir, bsdf render.BSDF) {
+	return shade(c.mat, c.frameAt(pt), in, width, rnd)
+}
+
+func (c *Cone) frameAt(pt geom.Vec) frame {
+	p := c.mtx.Inverse().MultPoint(pt)
+	rad := math.Sqrt(p.X*p.X + p.Z*p.Z)
+	if math.Abs(p.Y+0.5) < math.Abs(rad-(0.5-p.Y)/2) {
+		return localFrame(c.mtx, p, geom.Dir{0, -1, 0}, p.X+0.5, p.Z+0.5, geom.Vec{1, 0, 0}, geom.Vec{0, 0, 1})
+	}
+	normal, ok := geom.Vec{2 * p.X, (0.5 - p.Y) / 2, 2 * p.Z}.Unit()
+	if !ok {
+		normal = geom.Up
+	}
+	u, v := math.Atan2(p.X, p.Z)/(2*math.Pi)+0.5, p.Y+0.5
+	dpdu := geom.Vec{p.Z, 0, -p.X}.Scaled(2 * math.Pi)
+	dpdv := geom.Vec{0, 1, 0}
+	if rad > 0 {
+		dpdv = geom.Vec{-0.5 * p.X / rad, 1, -0.5 * p.Z / rad}
+	}
+	return localFrame(c.mtx, p, normal, u, v, dpdu, dpdv)
+}
+
+func (c *Cone) material() Material {
+	return c.mat
+}
+
+func (c *Cone) Bounds() *geom.Bounds {
+	return c.bounds
+}
+
+func (c *Cone) Lights() []render.Object {
+	if !c.mat.Light().Zero() {
+		return []render.Object{c}
+	}
+	return nil
+}
+
+func (c *Cone) Light() rgb.Energy {
+	return c.mat.Light()
+}
+
+// LightAt returns the light emitted at a point on the Cone.
+func (c *Cone) LightAt(pt geom.Vec) rgb.Energy {
+	return emit(c.mat, c.frameAt(pt))
+}
+
+func (c *Cone) Absorb() rgb.Energy {
+	return c.mat.Absorb()
+}
+
+func (c *Cone) Shift(v geom.Vec) *Cone {
+	return c.transform(geom.Shift(v))
+}
+
+func (c *Cone) Scale(v geom.Vec) *Cone {
+	return c.transform(geom.Scale(v))
+}
+
+func (c *Cone) Rotate(v geom.Vec) *Cone {
+	return c.transform(geom.Rotate(v))
+}
+
+func (c *Cone) Center() geom.Vec {
+	return c.mtx.MultPoint(geom.Vec{})
+}
+
+func (c *Cone) transform(m *geom.Mtx) *Cone {
+	c.mtx = c.mtx.Mult(m)
+	c.bounds = transformedBounds(c.mtx, geom.Vec{-0.5, -0.5, -0.5}, geom.Vec{0.5, 0.5, 0.5})
+	return c
+}
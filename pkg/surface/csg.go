@@ -0,0 +1,219 @@
+package surface
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/render"
+	"github.com/hunterloftis/pbr/pkg/rgb"
+)
+
+// Solid is a closed Surface that can report every span of a ray that lies within it.
+type Solid interface {
+	render.Surface
+	Spans(ray *geom.Ray) []Span
+}
+
+// Span is the segment of a ray from where it enters a Solid to where it exits.
+type Span struct {
+	In, Out Hit
+}
+
+// Hit is the Object a ray crosses at Dist along the ray.
+// Dist is negative for crossings behind the ray's origin.
+type Hit struct {
+	Dist float64
+	Obj  render.Object
+}
+
+type operation int
+
+const (
+	union operation = iota
+	intersection
+	difference
+)
+
+// CSG combines two Solids with a boolean operation (Constructive Solid Geometry).
+// https://en.wikipedia.org/wiki/Constructive_solid_geometry
+type CSG struct {
+	op     operation
+	a, b   Solid
+	lights []render.Object
+	bounds *geom.Bounds
+}
+
+// NewUnion returns a Solid containing everything within a or b.
+func NewUnion(a, b Solid) *CSG {
+	return newCSG(union, a, b, geom.MergeBounds(a.Bounds(), b.Bounds()))
+}
+
+// NewIntersection returns a Solid containing everything within both a and b.
+func NewIntersection(a, b Solid) *CSG {
+	ba, bb := a.Bounds(), b.Bounds()
+	min := ba.Min.Max(bb.Min)
+	max := ba.Max.Min(bb.Max).Max(min)
+	return newCSG(intersection, a, b, geom.NewBounds(min, max))
+}
+
+// NewDifference returns a Solid containing everything within a that is not within b.
+// Surfaces carved out by b keep b's material, with their normals inverted.
+func NewDifference(a, b Solid) *CSG {
+	return newCSG(difference, a, b, a.Bounds())
+}
+
+// newCSG combines a and b with op.
+// Lights of a and b are kept whole unless they lie entirely outside of bounds,
+// so shadow rays aimed at their carved-away parts find no light and contribute nothing.
+func newCSG(op operation, a, b Solid, bounds *geom.Bounds) *CSG {
+	c := CSG{
+		op:     op,
+		a:      a,
+		b:      b,
+		bounds: bounds,
+	}
+	for _, lights := range [2][]render.Object{a.Lights(), b.Lights()} {
+		for _, l := range lights {
+			if l.Bounds().Overlaps(bounds) {
+				c.lights = append(c.lights, l)
+			}
+		}
+	}
+	return &c
+}
+
+func (c *CSG) Intersect(ray *geom.Ray, max float64) (obj render.Object, dist float64) {
+	if ok, near, _ := c.bounds.Check(ray); !ok || near >= max {
+		return nil, 0
+	}
+	for _, s := range c.Spans(ray) {
+		for _, h := range [2]Hit{s.In, s.Out} {
+			if h.Dist >= max {
+				return nil, 0
+			}
+			if h.Dist > bias {
+				return h.Obj, h.Dist
+			}
+		}
+	}
+	return nil, 0
+}
+
+// Spans sweeps through the boundaries of both Solids in order along the ray,
+// tracking whether the ray is within each, and records the spans within the combination.
+func (c *CSG) Spans(ray *geom.Ray) []Span {
+	as := c.a.Spans(ray)
+	bs := c.b.Spans(ray)
+	if len(as) == 0 && c.op != union {
+		return nil
+	}
+	type event struct {
+		hit   Hit
+		fromA bool
+		in    bool
+	}
+	events := make([]event, 0, 2*(len(as)+len(bs)))
+	for _, s := range as {
+		events = append(events, event{s.In, true, true}, event{s.Out, true, false})
+	}
+	for _, s := range bs {
+		events = append(events, event{s.In, false, true}, event{s.Out, false, false})
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].hit.Dist < events[j].hit.Dist
+	})
+	spans := make([]Span, 0, len(events)/2)
+	inA, inB, inside := false, false, false
+	var start Hit
+	for _, e := range events {
+		if e.fromA {
+			inA = e.in
+		} else {
+			inB = e.in
+		}
+		hit := e.hit
+		if c.op == difference && !e.fromA {
+			hit.Obj = invert(hit.Obj)
+		}
+		now := c.contains(inA, inB)
+		if now && !inside {
+			start = hit
+		} else if inside && !now {
+			spans = append(spans, Span{In: start, Out: hit})
+		}
+		inside = now
+	}
+	return spans
+}
+
+func (c *CSG) contains(inA, inB bool) bool {
+	switch c.op {
+	case intersection:
+		return inA && inB
+	case difference:
+		return inA && !inB
+	default:
+		return inA || inB
+	}
+}
+
+func (c *CSG) Lights() []render.Object {
+	return c.lights
+}
+
+func (c *CSG) Bounds() *geom.Bounds {
+	return c.bounds
+}
+
+// inverted is a shape with its normal flipped, used for surfaces carved out of a Solid.
+type inverted struct {
+	shape
+}
+
+func invert(obj render.Object) render.Object {
+	switch s := obj.(type) {
+	case inverted:
+		return s.shape
+	case shape:
+		return inverted{s}
+	}
+	return obj
+}
+
+func (i inverted) At(pt geom.Vec, in geom.Dir, width float64, rnd *rand.Rand) (normal geom.Dir, bsdf render.BSDF) {
+	return shade(i.material(), i.frameAt(pt), in, width, rnd)
+}
+
+// LightAt returns the light emitted at a point on the carved surface.
+func (i inverted) LightAt(pt geom.Vec) rgb.Energy {
+	return emit(i.material(), i.frameAt(pt))
+}
+
+func (i inverted) frameAt(pt geom.Vec) frame {
+	f := i.shape.frameAt(pt)
+	f.normal = f.normal.Inv()
+	return f
+}
+
+// signedDist converts a local-space distance t along dir into a signed world-space distance.
+func signedDist(mtx *geom.Mtx, dir geom.Dir, t float64) float64 {
+	return math.Copysign(mtx.MultDist(dir.Scaled(t)).Len(), t)
+}
+
+// convexSpans returns the single Span of a convex shape between its nearest and farthest local crossings.
+func convexSpans(obj render.Object, mtx *geom.Mtx, dir geom.Dir, ts []float64) []Span {
+	if len(ts) < 2 {
+		return nil
+	}
+	near, far := math.Inf(1), math.Inf(-1)
+	for _, t := range ts {
+		near = math.Min(near, t)
+		far = math.Max(far, t)
+	}
+	return []Span{{
+		In:  Hit{signedDist(mtx, dir, near), obj},
+		Out: Hit{signedDist(mtx, dir, far), obj},
+	}}
+}
@@ -0,0 +1,53 @@
+package surface
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/render"
+	"github.com/hunterloftis/pbr/pkg/rgb"
+	"github.com/hunterloftis/pbr/pkg/texture"
+)
+
+// glow is a Material whose light varies with u.
+type glow struct{}
+
+func (g glow) At(c texture.Coord, in, norm geom.Dir, rnd *rand.Rand) (geom.Dir, render.BSDF) {
+	return geom.Up, Lambert{}
+}
+
+func (g glow) Light() rgb.Energy {
+	return rgb.White
+}
+
+func (g glow) Absorb() rgb.Energy {
+	return rgb.Black
+}
+
+func (g glow) LightAt(c texture.Coord) rgb.Energy {
+	return rgb.White.Scaled(c.U)
+}
+
+func TestCSGLights(t *testing.T) {
+	far := UnitSphere(glow{}).Shift(geom.Vec{10, 0, 0})
+	if n := len(NewDifference(UnitCube(), far).Lights()); n != 0 {
+		t.Error("Expected a light outside of the difference to be dropped, got", n, "lights")
+	}
+	near := UnitSphere(glow{}).Shift(geom.Vec{0.5, 0, 0})
+	if n := len(NewDifference(UnitCube(), near).Lights()); n != 1 {
+		t.Error("Expected a light carving the difference to be kept, got", n, "lights")
+	}
+}
+
+func TestInvertedLightAt(t *testing.T) {
+	s := UnitSphere(glow{})
+	e, ok := invert(s).(render.Emitter)
+	if !ok {
+		t.Fatal("Expected an inverted Emitter")
+	}
+	pt := geom.Vec{0, 0, 0.5}
+	if got, want := e.LightAt(pt), s.LightAt(pt); got != want {
+		t.Error("Expected", want, "got", got)
+	}
+}
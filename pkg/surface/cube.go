@@ -67,32 +67,49 @@ func (c *Cube) Intersect(ray *geom.Ray, max float64) (obj render.Object, dist fl
 	return nil, 0
 }
 
+// Spans returns the span of the ray within the Cube.
+func (c *Cube) Spans(ray *geom.Ray) []Span {
+	r := c.mtx.Inverse().MultRay(ray)
+	tmin := math.Inf(-1)
+	tmax := math.Inf(1)
+	for a := 0; a < 3; a++ {
+		t0 := (-0.5 - r.OrArray[a]) * r.InvArray[a]
+		t1 := (0.5 - r.OrArray[a]) * r.InvArray[a]
+		if r.InvArray[a] < 0 {
+			t0, t1 = t1, t0
+		}
+		tmin = math.Max(tmin, t0)
+		tmax = math.Min(tmax, t1)
+		if tmax < tmin {
+			return nil
+		}
+	}
+	return convexSpans(c, c.mtx, r.Dir, []float64{tmin, tmax})
+}
+
 // At returns the normal geom.Vec at this point on the Surface
-func (c *Cube) At(pt geom.Vec, in geom.Dir, rnd *rand.Rand) (normal geom.Dir, bsdf render.BSDF) {
-	normal = geom.Dir{}
+func (c *Cube) At(pt geom.Vec, in geom.Dir, width float64, rnd *rand.Rand) (normal geom.Dir, bsdf render.BSDF) {
+	return shade(c.mat, c.frameAt(pt), in, width, rnd)
+}
+
+func (c *Cube) frameAt(pt geom.Vec) frame {
 	i := c.mtx.Inverse()  // global to local transform
 	p1 := i.MultPoint(pt) // translate point into local space
 	abs := p1.Abs()
-	u, v := 0.0, 0.0
 	switch {
 	case abs.X > abs.Y && abs.X > abs.Z:
-		normal = geom.Dir{math.Copysign(1, p1.X), 0, 0}
-		u = p1.Z + 0.5
-		v = p1.Y + 0.5
+		normal := geom.Dir{math.Copysign(1, p1.X), 0, 0}
+		return localFrame(c.mtx, p1, normal, p1.Z+0.5, p1.Y+0.5, geom.Vec{0, 0, 1}, geom.Vec{0, 1, 0})
 	case abs.Y > abs.Z:
-		normal = geom.Dir{0, math.Copysign(1, p1.Y), 0}
-		u = p1.Z + 0.5
-		v = p1.X + 0.5
-	default:
-		normal = geom.Dir{0, 0, math.Copysign(1, p1.Z)}
-		u = p1.X + 0.5
-		v = p1.Y + 0.5
+		normal := geom.Dir{0, math.Copysign(1, p1.Y), 0}
+		return localFrame(c.mtx, p1, normal, p1.Z+0.5, p1.X+0.5, geom.Vec{0, 0, 1}, geom.Vec{1, 0, 0})
 	}
-	n := c.mtx.MultDir(normal)
-	n2, bsdf := c.mat.At(u, v, in, n, rnd)
-	_ = n2
-	normal = n // TODO: combine n and n2
-	return normal, bsdf
+	normal := geom.Dir{0, 0, math.Copysign(1, p1.Z)}
+	return localFrame(c.mtx, p1, normal, p1.X+0.5, p1.Y+0.5, geom.Vec{1, 0, 0}, geom.Vec{0, 1, 0})
+}
+
+func (c *Cube) material() Material {
+	return c.mat
 }
 
 func (c *Cube) Bounds() *geom.Bounds {
@@ -110,8 +127,13 @@ func (c *Cube) Light() rgb.Energy {
 	return c.mat.Light()
 }
 
-func (c *Cube) Transmit() rgb.Energy {
-	return c.mat.Transmit()
+// LightAt returns the light emitted at a point on the Cube.
+func (c *Cube) LightAt(pt geom.Vec) rgb.Energy {
+	return emit(c.mat, c.frameAt(pt))
+}
+
+func (c *Cube) Absorb() rgb.Energy {
+	return c.mat.Absorb()
 }
 
 func (c *Cube) Shift(v geom.Vec) *Cube {
@@ -132,17 +154,6 @@ func (c *Cube) Center() geom.Vec {
 
 func (c *Cube) transform(m *geom.Mtx) *Cube {
 	c.mtx = c.mtx.Mult(m)
-	min := c.mtx.MultPoint(geom.Vec{})
-	max := c.mtx.MultPoint(geom.Vec{})
-	for x := -0.5; x <= 0.5; x += 1 {
-		for y := -0.5; y <= 0.5; y += 1 {
-			for z := -0.5; z <= 0.5; z += 1 {
-				pt := c.mtx.MultPoint(geom.Vec{x, y, z})
-				min = min.Min(pt)
-				max = max.Max(pt)
-			}
-		}
-	}
-	c.bounds = geom.NewBounds(min, max)
+	c.bounds = transformedBounds(c.mtx, geom.Vec{-0.5, -0.5, -0.5}, geom.Vec{0.5, 0.5, 0.5})
 	return c
 }
@@ -0,0 +1,140 @@
+package surface
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/render"
+	"github.com/hunterloftis/pbr/pkg/rgb"
+)
+
+// Cylinder describes a capped cylinder of diameter 1 and height 1 around the Y axis
+type Cylinder struct {
+	mtx    *geom.Mtx
+	mat    Material
+	bounds *geom.Bounds
+}
+
+// UnitCylinder returns a pointer to a new 1x1x1 Cylinder Surface with material and optional transforms.
+func UnitCylinder(m ...Material) *Cylinder {
+	c := &Cylinder{
+		mtx: geom.Identity(),
+		mat: &DefaultMaterial{},
+	}
+	if len(m) > 0 {
+		c.mat = m[0]
+	}
+	return c.transform(geom.Identity())
+}
+
+func (c *Cylinder) Intersect(ray *geom.Ray, max float64) (obj render.Object, dist float64) {
+	if ok, near, _ := c.bounds.Check(ray); !ok || near >= max {
+		return nil, 0
+	}
+	r := c.mtx.Inverse().MultRay(ray)
+	if dist, ok := nearest(c.mtx, r.Dir, max, c.crossings(r)...); ok {
+		return c, dist
+	}
+	return nil, 0
+}
+
+// Spans returns the span of the ray within the Cylinder.
+func (c *Cylinder) Spans(ray *geom.Ray) []Span {
+	r := c.mtx.Inverse().MultRay(ray)
+	return convexSpans(c, c.mtx, r.Dir, c.crossings(r))
+}
+
+// crossings returns the local distances along r at which it crosses the side and caps.
+func (c *Cylinder) crossings(r *geom.Ray) []float64 {
+	ts := make([]float64, 0, 4)
+	o, d := r.Origin, r.Dir
+	for _, t := range solveQuadratic(d.X*d.X+d.Z*d.Z, 2*(o.X*d.X+o.Z*d.Z), o.X*o.X+o.Z*o.Z-0.25) {
+		if y := o.Y + t*d.Y; y >= -0.5 && y <= 0.5 {
+			ts = append(ts, t)
+		}
+	}
+	ts = append(ts, capHits(r, -0.5, 0.5)...)
+	return append(ts, capHits(r, 0.5, 0.5)...)
+}
+
+// At returns the normal geom.Vec at this point on the Surface
+func (c *Cylinder) At(pt geom.Vec, in geom.Dir, width float64, rnd *rand.Rand) (normal geom.Dir, bsdf render.BSDF) {
+	return shade(c.mat, c.frameAt(pt), in, width, rnd)
+}
+
+func (c *Cylinder) frameAt(pt geom.Vec) frame {
+	p := c.mtx.Inverse().MultPoint(pt)
+	rad := math.Sqrt(p.X*p.X + p.Z*p.Z)
+	if math.Abs(math.Abs(p.Y)-0.5) < math.Abs(rad-0.5) {
+		normal := geom.Dir{0, math.Copysign(1, p.Y), 0}
+		return localFrame(c.mtx, p, normal, p.X+0.5, p.Z+0.5, geom.Vec{1, 0, 0}, geom.Vec{0, 0, 1})
+	}
+	normal, _ := geom.Vec{p.X, 0, p.Z}.Unit()
+	u, v := math.Atan2(p.X, p.Z)/(2*math.Pi)+0.5, p.Y+0.5
+	dpdu := geom.Vec{p.Z, 0, -p.X}.Scaled(2 * math.Pi)
+	return localFrame(c.mtx, p, normal, u, v, dpdu, geom.Vec{0, 1, 0})
+}
+
+func (c *Cylinder) material() Material {
+	return c.mat
+}
+
+func (c *Cylinder) Bounds() *geom.Bounds {
+	return c.bounds
+}
+
+func (c *Cylinder) Lights() []render.Object {
+	if !c.mat.Light().Zero() {
+		return []render.Object{c}
+	}
+	return nil
+}
+
+func (c *Cylinder) Light() rgb.Energy {
+	return c.mat.Light()
+}
+
+// LightAt returns the light emitted at a point on the Cylinder.
+func (c *Cylinder) LightAt(pt geom.Vec) rgb.Energy {
+	return emit(c.mat, c.frameAt(pt))
+}
+
+func (c *Cylinder) Absorb() rgb.Energy {
+	return c.mat.Absorb()
+}
+
+func (c *Cylinder) Shift(v geom.Vec) *Cylinder {
+	return c.transform(geom.Shift(v))
+}
+
+func (c *Cylinder) Scale(v geom.Vec) *Cylinder {
+	return c.transform(geom.Scale(v))
+}
+
+func (c *Cylinder) Rotate(v geom.Vec) *Cylinder {
+	return c.transform(geom.Rotate(v))
+}
+
+func (c *Cylinder) Center() geom.Vec {
+	return c.mtx.MultPoint(geom.Vec{})
+}
+
+func (c *Cylinder) transform(m *geom.Mtx) *Cylinder {
+	c.mtx = c.mtx.Mult(m)
+	c.bounds = transformedBounds(c.mtx, geom.Vec{-0.5, -0.5, -0.5}, geom.Vec{0.5, 0.5, 0.5})
+	return c
+}
+
+// capHits returns the local distance along r to a horizontal disk cap at height y, if r crosses it.
+func capHits(r *geom.Ray, y, radius float64) []float64 {
+	if math.Abs(r.Dir.Y) < bias {
+		return nil
+	}
+	t := (y - r.Origin.Y) / r.Dir.Y
+	pt := r.Moved(t)
+	if pt.X*pt.X+pt.Z*pt.Z > radius*radius {
+		return nil
+	}
+	return []float64{t}
+}
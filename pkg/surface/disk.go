@@ -0,0 +1,108 @@
+package surface
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/render"
+	"github.com/hunterloftis/pbr/pkg/rgb"
+)
+
+// Disk describes a circle of diameter 1 on the XZ plane with a normal of geom.Up
+type Disk struct {
+	mtx    *geom.Mtx
+	mat    Material
+	bounds *geom.Bounds
+}
+
+// UnitDisk returns a pointer to a new Disk Surface of diameter 1 with material and optional transforms.
+func UnitDisk(m ...Material) *Disk {
+	d := &Disk{
+		mtx: geom.Identity(),
+		mat: &DefaultMaterial{},
+	}
+	if len(m) > 0 {
+		d.mat = m[0]
+	}
+	return d.transform(geom.Identity())
+}
+
+func (d *Disk) Intersect(ray *geom.Ray, max float64) (obj render.Object, dist float64) {
+	if ok, near, _ := d.bounds.Check(ray); !ok || near >= max {
+		return nil, 0
+	}
+	r := d.mtx.Inverse().MultRay(ray)
+	if math.Abs(r.Dir.Y) < bias {
+		return nil, 0
+	}
+	t := -r.Origin.Y / r.Dir.Y
+	pt := r.Moved(t)
+	if pt.X*pt.X+pt.Z*pt.Z > 0.25 {
+		return nil, 0
+	}
+	if dist, ok := nearest(d.mtx, r.Dir, max, t); ok {
+		return d, dist
+	}
+	return nil, 0
+}
+
+// At returns the normal geom.Vec at this point on the Surface
+func (d *Disk) At(pt geom.Vec, in geom.Dir, width float64, rnd *rand.Rand) (normal geom.Dir, bsdf render.BSDF) {
+	return shade(d.mat, d.frameAt(pt), in, width, rnd)
+}
+
+func (d *Disk) frameAt(pt geom.Vec) frame {
+	p1 := d.mtx.Inverse().MultPoint(pt)
+	return localFrame(d.mtx, p1, geom.Up, p1.X+0.5, p1.Z+0.5, geom.Vec{1, 0, 0}, geom.Vec{0, 0, 1})
+}
+
+func (d *Disk) material() Material {
+	return d.mat
+}
+
+func (d *Disk) Bounds() *geom.Bounds {
+	return d.bounds
+}
+
+func (d *Disk) Lights() []render.Object {
+	if !d.mat.Light().Zero() {
+		return []render.Object{d}
+	}
+	return nil
+}
+
+func (d *Disk) Light() rgb.Energy {
+	return d.mat.Light()
+}
+
+// LightAt returns the light emitted at a point on the Disk.
+func (d *Disk) LightAt(pt geom.Vec) rgb.Energy {
+	return emit(d.mat, d.frameAt(pt))
+}
+
+func (d *Disk) Absorb() rgb.Energy {
+	return d.mat.Absorb()
+}
+
+func (d *Disk) Shift(v geom.Vec) *Disk {
+	return d.transform(geom.Shift(v))
+}
+
+func (d *Disk) Scale(v geom.Vec) *Disk {
+	return d.transform(geom.Scale(v))
+}
+
+func (d *Disk) Rotate(v geom.Vec) *Disk {
+	return d.transform(geom.Rotate(v))
+}
+
+func (d *Disk) Center() geom.Vec {
+	return d.mtx.MultPoint(geom.Vec{})
+}
+
+func (d *Disk) transform(m *geom.Mtx) *Disk {
+	d.mtx = d.mtx.Mult(m)
+	d.bounds = transformedBounds(d.mtx, geom.Vec{-0.5, 0, -0.5}, geom.Vec{0.5, 0, 0.5})
+	return d
+}
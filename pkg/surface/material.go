@@ -7,26 +7,48 @@ import (
 	"github.com/hunterloftis/pbr/pkg/geom"
 	"github.com/hunterloftis/pbr/pkg/render"
 	"github.com/hunterloftis/pbr/pkg/rgb"
+	"github.com/hunterloftis/pbr/pkg/texture"
 )
 
+// Material describes the surface properties of an Object.
+// At returns a tangent-space normal, in which geom.Up leaves the surface normal unchanged.
 type Material interface {
-	At(u, v float64, in, norm geom.Dir, rnd *rand.Rand) (normal geom.Dir, bsdf render.BSDF)
+	At(c texture.Coord, in, norm geom.Dir, rnd *rand.Rand) (normal geom.Dir, bsdf render.BSDF)
 	Light() rgb.Energy
-	Transmit() rgb.Energy
+	Absorb() rgb.Energy
+}
+
+// Emitter is a Material whose Light varies over its surface.
+type Emitter interface {
+	LightAt(c texture.Coord) rgb.Energy
+}
+
+// TwoSided is a Material that faces both ways, like a leaf or an open mesh,
+// so rays strike its front from either side.
+type TwoSided interface {
+	TwoSided() bool
+}
+
+// emit returns the light emitted by material m at frame f.
+func emit(m Material, f frame) rgb.Energy {
+	if e, ok := m.(Emitter); ok {
+		return e.LightAt(f.coord(0))
+	}
+	return m.Light()
 }
 
 type DefaultMaterial struct {
 }
 
-func (d *DefaultMaterial) At(u, v float64, in, norm geom.Dir, rnd *rand.Rand) (normal geom.Dir, bsdf render.BSDF) {
-	return norm, Lambert{}
+func (d *DefaultMaterial) At(c texture.Coord, in, norm geom.Dir, rnd *rand.Rand) (normal geom.Dir, bsdf render.BSDF) {
+	return geom.Up, Lambert{}
 }
 
 func (d *DefaultMaterial) Light() rgb.Energy {
 	return rgb.Black
 }
 
-func (d *DefaultMaterial) Transmit() rgb.Energy {
+func (d *DefaultMaterial) Absorb() rgb.Energy {
 	return rgb.Black
 }
 
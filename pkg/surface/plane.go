@@ -0,0 +1,107 @@
+package surface
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/render"
+	"github.com/hunterloftis/pbr/pkg/rgb"
+)
+
+// planeSize is the extent of a Plane's bounds.
+// The intersection itself is analytic, but finite bounds keep Trees and shadow rays well-behaved.
+const planeSize = 1e6
+
+// Plane describes an infinite plane through the origin with a normal of geom.Up
+type Plane struct {
+	mtx    *geom.Mtx
+	mat    Material
+	bounds *geom.Bounds
+}
+
+// UnitPlane returns a pointer to a new Plane Surface with material and optional transforms.
+func UnitPlane(m ...Material) *Plane {
+	p := &Plane{
+		mtx: geom.Identity(),
+		mat: &DefaultMaterial{},
+	}
+	if len(m) > 0 {
+		p.mat = m[0]
+	}
+	return p.transform(geom.Identity())
+}
+
+func (p *Plane) Intersect(ray *geom.Ray, max float64) (obj render.Object, dist float64) {
+	if ok, near, _ := p.bounds.Check(ray); !ok || near >= max {
+		return nil, 0
+	}
+	r := p.mtx.Inverse().MultRay(ray)
+	if math.Abs(r.Dir.Y) < bias {
+		return nil, 0
+	}
+	if dist, ok := nearest(p.mtx, r.Dir, max, -r.Origin.Y/r.Dir.Y); ok {
+		return p, dist
+	}
+	return nil, 0
+}
+
+// At returns the normal geom.Vec at this point on the Surface
+func (p *Plane) At(pt geom.Vec, in geom.Dir, width float64, rnd *rand.Rand) (normal geom.Dir, bsdf render.BSDF) {
+	return shade(p.mat, p.frameAt(pt), in, width, rnd)
+}
+
+func (p *Plane) frameAt(pt geom.Vec) frame {
+	p1 := p.mtx.Inverse().MultPoint(pt)
+	return localFrame(p.mtx, p1, geom.Up, p1.X, p1.Z, geom.Vec{1, 0, 0}, geom.Vec{0, 0, 1})
+}
+
+func (p *Plane) material() Material {
+	return p.mat
+}
+
+func (p *Plane) Bounds() *geom.Bounds {
+	return p.bounds
+}
+
+func (p *Plane) Lights() []render.Object {
+	if !p.mat.Light().Zero() {
+		return []render.Object{p}
+	}
+	return nil
+}
+
+func (p *Plane) Light() rgb.Energy {
+	return p.mat.Light()
+}
+
+// LightAt returns the light emitted at a point on the Plane.
+func (p *Plane) LightAt(pt geom.Vec) rgb.Energy {
+	return emit(p.mat, p.frameAt(pt))
+}
+
+func (p *Plane) Absorb() rgb.Energy {
+	return p.mat.Absorb()
+}
+
+func (p *Plane) Shift(v geom.Vec) *Plane {
+	return p.transform(geom.Shift(v))
+}
+
+func (p *Plane) Scale(v geom.Vec) *Plane {
+	return p.transform(geom.Scale(v))
+}
+
+func (p *Plane) Rotate(v geom.Vec) *Plane {
+	return p.transform(geom.Rotate(v))
+}
+
+func (p *Plane) Center() geom.Vec {
+	return p.mtx.MultPoint(geom.Vec{})
+}
+
+func (p *Plane) transform(m *geom.Mtx) *Plane {
+	p.mtx = p.mtx.Mult(m)
+	p.bounds = transformedBounds(p.mtx, geom.Vec{-planeSize, 0, -planeSize}, geom.Vec{planeSize, 0, planeSize})
+	return p
+}
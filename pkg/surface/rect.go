@@ -0,0 +1,109 @@
+package surface
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/render"
+	"github.com/hunterloftis/pbr/pkg/rgb"
+)
+
+// Rect describes a 1x1 square on the XZ plane with a normal of geom.Up.
+// Emissive Rects make convenient area lights.
+type Rect struct {
+	mtx    *geom.Mtx
+	mat    Material
+	bounds *geom.Bounds
+}
+
+// UnitRect returns a pointer to a new 1x1 Rect Surface with material and optional transforms.
+func UnitRect(m ...Material) *Rect {
+	r := &Rect{
+		mtx: geom.Identity(),
+		mat: &DefaultMaterial{},
+	}
+	if len(m) > 0 {
+		r.mat = m[0]
+	}
+	return r.transform(geom.Identity())
+}
+
+func (r *Rect) Intersect(ray *geom.Ray, max float64) (obj render.Object, dist float64) {
+	if ok, near, _ := r.bounds.Check(ray); !ok || near >= max {
+		return nil, 0
+	}
+	lr := r.mtx.Inverse().MultRay(ray)
+	if math.Abs(lr.Dir.Y) < bias {
+		return nil, 0
+	}
+	t := -lr.Origin.Y / lr.Dir.Y
+	pt := lr.Moved(t)
+	if math.Abs(pt.X) > 0.5 || math.Abs(pt.Z) > 0.5 {
+		return nil, 0
+	}
+	if dist, ok := nearest(r.mtx, lr.Dir, max, t); ok {
+		return r, dist
+	}
+	return nil, 0
+}
+
+// At returns the normal geom.Vec at this point on the Surface
+func (r *Rect) At(pt geom.Vec, in geom.Dir, width float64, rnd *rand.Rand) (normal geom.Dir, bsdf render.BSDF) {
+	return shade(r.mat, r.frameAt(pt), in, width, rnd)
+}
+
+func (r *Rect) frameAt(pt geom.Vec) frame {
+	p1 := r.mtx.Inverse().MultPoint(pt)
+	return localFrame(r.mtx, p1, geom.Up, p1.X+0.5, p1.Z+0.5, geom.Vec{1, 0, 0}, geom.Vec{0, 0, 1})
+}
+
+func (r *Rect) material() Material {
+	return r.mat
+}
+
+func (r *Rect) Bounds() *geom.Bounds {
+	return r.bounds
+}
+
+func (r *Rect) Lights() []render.Object {
+	if !r.mat.Light().Zero() {
+		return []render.Object{r}
+	}
+	return nil
+}
+
+func (r *Rect) Light() rgb.Energy {
+	return r.mat.Light()
+}
+
+// LightAt returns the light emitted at a point on the Rect.
+func (r *Rect) LightAt(pt geom.Vec) rgb.Energy {
+	return emit(r.mat, r.frameAt(pt))
+}
+
+func (r *Rect) Absorb() rgb.Energy {
+	return r.mat.Absorb()
+}
+
+func (r *Rect) Shift(v geom.Vec) *Rect {
+	return r.transform(geom.Shift(v))
+}
+
+func (r *Rect) Scale(v geom.Vec) *Rect {
+	return r.transform(geom.Scale(v))
+}
+
+func (r *Rect) Rotate(v geom.Vec) *Rect {
+	return r.transform(geom.Rotate(v))
+}
+
+func (r *Rect) Center() geom.Vec {
+	return r.mtx.MultPoint(geom.Vec{})
+}
+
+func (r *Rect) transform(m *geom.Mtx) *Rect {
+	r.mtx = r.mtx.Mult(m)
+	r.bounds = transformedBounds(r.mtx, geom.Vec{-0.5, 0, -0.5}, geom.Vec{0.5, 0, 0.5})
+	return r
+}
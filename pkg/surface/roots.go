@@ -0,0 +1,99 @@
+package surface
+
+import (
+	"math"
+	"sort"
+)
+
+// solveQuadratic returns the real roots of a*t^2 + b*t + c in ascending order.
+func solveQuadratic(a, b, c float64) []float64 {
+	if a == 0 {
+		if b == 0 {
+			return nil
+		}
+		return []float64{-c / b}
+	}
+	det := b*b - 4*a*c
+	if det < 0 {
+		return nil
+	}
+	// https://en.wikipedia.org/wiki/Loss_of_significance#A_better_algorithm
+	q := -0.5 * (b + math.Copysign(math.Sqrt(det), b))
+	if q == 0 {
+		return []float64{0}
+	}
+	t0, t1 := q/a, c/q
+	if t0 > t1 {
+		t0, t1 = t1, t0
+	}
+	return []float64{t0, t1}
+}
+
+// solveCubic returns the real roots of t^3 + a*t^2 + b*t + c.
+// https://en.wikipedia.org/wiki/Cubic_equation#Trigonometric_and_hyperbolic_solutions
+func solveCubic(a, b, c float64) []float64 {
+	q := (a*a - 3*b) / 9
+	r := (2*a*a*a - 9*a*b + 27*c) / 54
+	if r*r < q*q*q {
+		theta := math.Acos(r / math.Sqrt(q*q*q))
+		s := -2 * math.Sqrt(q)
+		return []float64{
+			s*math.Cos(theta/3) - a/3,
+			s*math.Cos((theta+2*math.Pi)/3) - a/3,
+			s*math.Cos((theta-2*math.Pi)/3) - a/3,
+		}
+	}
+	u := -math.Cbrt(r + math.Copysign(math.Sqrt(r*r-q*q*q), r))
+	v := 0.0
+	if u != 0 {
+		v = q / u
+	}
+	return []float64{u + v - a/3}
+}
+
+// solveQuartic returns the real roots of t^4 + a*t^3 + b*t^2 + c*t + d in ascending order.
+// Each root is polished with Newton's method to recover precision lost in Ferrari's method.
+// https://en.wikipedia.org/wiki/Quartic_function#Ferrari's_solution
+func solveQuartic(a, b, c, d float64) []float64 {
+	// depressed quartic y^4 + p*y^2 + q*y + r, with t = y - a/4
+	a2 := a * a
+	p := b - 3*a2/8
+	q := c - a*b/2 + a2*a/8
+	r := d - a*c/4 + a2*b/16 - 3*a2*a2/256
+	roots := make([]float64, 0, 4)
+	if math.Abs(q) < 1e-12 {
+		// biquadratic
+		for _, z := range solveQuadratic(1, p, r) {
+			if z >= 0 {
+				s := math.Sqrt(z)
+				roots = append(roots, s, -s)
+			}
+		}
+	} else {
+		// find a positive root m of the resolvent cubic 8m^3 + 8pm^2 + (2p^2 - 8r)m - q^2
+		m := 0.0
+		for _, x := range solveCubic(p, p*p/4-r, -q*q/8) {
+			m = math.Max(m, x)
+		}
+		if m <= 0 {
+			return nil
+		}
+		s := math.Sqrt(2 * m)
+		roots = append(roots, solveQuadratic(1, s, p/2+m-q/(2*s))...)
+		roots = append(roots, solveQuadratic(1, -s, p/2+m+q/(2*s))...)
+	}
+	for i, y := range roots {
+		t := y - a/4
+		for n := 0; n < 2; n++ {
+			f := (((t+a)*t+b)*t+c)*t + d
+			df := ((4*t+3*a)*t+2*b)*t + c
+			if df == 0 {
+				break
+			}
+			t -= f / df
+		}
+		roots[i] = t
+	}
+	sort.Float64s(roots)
+	return roots
+}
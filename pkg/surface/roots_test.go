@@ -0,0 +1,26 @@
+package surface
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSolveQuartic(t *testing.T) {
+	// (t - 1)(t - 2)(t - 3)(t - 4)
+	roots := solveQuartic(-10, 35, -50, 24)
+	if len(roots) != 4 {
+		t.Fatal("Expected 4 roots, got", roots)
+	}
+	for i, want := range []float64{1, 2, 3, 4} {
+		if math.Abs(roots[i]-want) > 1e-9 {
+			t.Error("Expected", want, "got", roots[i])
+		}
+	}
+}
+
+func TestSolveQuarticNoRoots(t *testing.T) {
+	// t^4 + 1
+	if roots := solveQuartic(0, 0, 0, 1); len(roots) != 0 {
+		t.Error("Expected no roots, got", roots)
+	}
+}
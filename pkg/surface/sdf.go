@@ -0,0 +1,172 @@
+package surface
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/render"
+	"github.com/hunterloftis/pbr/pkg/rgb"
+)
+
+const (
+	sdfSteps   = 512  // maximum sphere tracing iterations per ray
+	sdfEpsilon = 1e-5 // hit threshold, relative to the radius of the SDF's bounds
+)
+
+// SDF describes a surface implicitly, as the zero set of a signed distance function.
+// The function returns negative values inside and positive values outside the surface,
+// and must not overestimate the distance to the surface.
+// See package sdf for a library of distance functions.
+type SDF struct {
+	dist   func(geom.Vec) float64
+	local  *geom.Bounds
+	eps    float64
+	mtx    *geom.Mtx
+	mat    Material
+	bounds *geom.Bounds
+}
+
+// NewSDF returns a pointer to a new SDF Surface with material and optional transforms.
+// The distance function must be contained within the given local-space bounds.
+func NewSDF(dist func(geom.Vec) float64, bounds *geom.Bounds, m ...Material) *SDF {
+	s := &SDF{
+		dist:  dist,
+		local: bounds,
+		eps:   sdfEpsilon * math.Max(bounds.Radius, bias),
+		mtx:   geom.Identity(),
+		mat:   &DefaultMaterial{},
+	}
+	if len(m) > 0 {
+		s.mat = m[0]
+	}
+	return s.transform(geom.Identity())
+}
+
+// Intersect sphere traces the distance function along the ray.
+// Rays that begin on the surface must first escape it before they can register a hit,
+// and rays that begin inside the surface trace the negated distance.
+// https://graphics.stanford.edu/courses/cs348b-20-spring-content/uploads/hart.pdf
+func (s *SDF) Intersect(ray *geom.Ray, max float64) (obj render.Object, dist float64) {
+	if ok, near, _ := s.bounds.Check(ray); !ok || near >= max {
+		return nil, 0
+	}
+	r := s.mtx.Inverse().MultRay(ray)
+	ok, near, far := s.local.Check(r)
+	if !ok {
+		return nil, 0
+	}
+	t := math.Max(0, near)
+	d := s.dist(r.Moved(t))
+	sign := math.Copysign(1, d)
+	escaped := math.Abs(d) >= s.eps
+	if !escaped {
+		// on the surface, the ray is inside if it heads inward, whichever side of the zero set it starts on
+		sign = 1
+		if r.Dir.Dot(s.gradient(r.Moved(t))) < 0 {
+			sign = -1
+		}
+	}
+	for i := 0; i < sdfSteps && t <= far; i++ {
+		d := sign * s.dist(r.Moved(t))
+		if d < s.eps {
+			if escaped {
+				if dist, ok := nearest(s.mtx, r.Dir, max, t); ok {
+					return s, dist
+				}
+				return nil, 0
+			}
+		} else {
+			escaped = true
+		}
+		t += math.Max(d, s.eps)
+	}
+	return nil, 0
+}
+
+// At returns the normal geom.Vec at this point on the Surface
+func (s *SDF) At(pt geom.Vec, in geom.Dir, width float64, rnd *rand.Rand) (normal geom.Dir, bsdf render.BSDF) {
+	return shade(s.mat, s.frameAt(pt), in, width, rnd)
+}
+
+func (s *SDF) frameAt(pt geom.Vec) frame {
+	p := s.mtx.Inverse().MultPoint(pt)
+	normal := s.gradient(p)
+	abs := geom.Vec(normal).Abs()
+	switch {
+	case abs.X > abs.Y && abs.X > abs.Z:
+		return localFrame(s.mtx, p, normal, p.Z, p.Y, geom.Vec{0, 0, 1}, geom.Vec{0, 1, 0})
+	case abs.Y > abs.Z:
+		return localFrame(s.mtx, p, normal, p.Z, p.X, geom.Vec{0, 0, 1}, geom.Vec{1, 0, 0})
+	}
+	return localFrame(s.mtx, p, normal, p.X, p.Y, geom.Vec{1, 0, 0}, geom.Vec{0, 1, 0})
+}
+
+func (s *SDF) material() Material {
+	return s.mat
+}
+
+// gradient estimates the normalized gradient of the distance function with four samples.
+// http://iquilezles.org/www/articles/normalsSDF/normalsSDF.htm
+func (s *SDF) gradient(p geom.Vec) geom.Dir {
+	h := s.eps
+	k0 := geom.Vec{1, -1, -1}
+	k1 := geom.Vec{-1, -1, 1}
+	k2 := geom.Vec{-1, 1, -1}
+	k3 := geom.Vec{1, 1, 1}
+	g := k0.Scaled(s.dist(p.Plus(k0.Scaled(h))))
+	g = g.Plus(k1.Scaled(s.dist(p.Plus(k1.Scaled(h)))))
+	g = g.Plus(k2.Scaled(s.dist(p.Plus(k2.Scaled(h)))))
+	g = g.Plus(k3.Scaled(s.dist(p.Plus(k3.Scaled(h)))))
+	n, ok := g.Unit()
+	if !ok {
+		return geom.Up
+	}
+	return n
+}
+
+func (s *SDF) Bounds() *geom.Bounds {
+	return s.bounds
+}
+
+func (s *SDF) Lights() []render.Object {
+	if !s.mat.Light().Zero() {
+		return []render.Object{s}
+	}
+	return nil
+}
+
+func (s *SDF) Light() rgb.Energy {
+	return s.mat.Light()
+}
+
+// LightAt returns the light emitted at a point on the SDF.
+func (s *SDF) LightAt(pt geom.Vec) rgb.Energy {
+	return emit(s.mat, s.frameAt(pt))
+}
+
+func (s *SDF) Absorb() rgb.Energy {
+	return s.mat.Absorb()
+}
+
+func (s *SDF) Shift(v geom.Vec) *SDF {
+	return s.transform(geom.Shift(v))
+}
+
+func (s *SDF) Scale(v geom.Vec) *SDF {
+	return s.transform(geom.Scale(v))
+}
+
+func (s *SDF) Rotate(v geom.Vec) *SDF {
+	return s.transform(geom.Rotate(v))
+}
+
+func (s *SDF) Center() geom.Vec {
+	return s.mtx.MultPoint(s.local.Center)
+}
+
+func (s *SDF) transform(m *geom.Mtx) *SDF {
+	s.mtx = s.mtx.Mult(m)
+	s.bounds = transformedBounds(s.mtx, s.local.Min, s.local.Max)
+	return s
+}
@@ -0,0 +1,34 @@
+package surface
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+)
+
+// spheres returns an SDF of two unit spheres centered 4 units apart on the X axis.
+func spheres() *SDF {
+	dist := func(p geom.Vec) float64 {
+		a := p.Minus(geom.Vec{-2, 0, 0}).Len() - 1
+		b := p.Minus(geom.Vec{2, 0, 0}).Len() - 1
+		return math.Min(a, b)
+	}
+	return NewSDF(dist, geom.NewBounds(geom.Vec{-3, -1, -1}, geom.Vec{3, 1, 1}))
+}
+
+func TestSDFLeavingSurface(t *testing.T) {
+	s := spheres()
+	// rays leaving the first sphere from just inside, on, and just outside its surface all reach the second
+	for _, x := range []float64{-1 - 1e-7, -1, -1 + 1e-7} {
+		ray := geom.NewRay(geom.Vec{x, 0, 0}, geom.Dir{1, 0, 0})
+		obj, dist := s.Intersect(ray, math.Inf(1))
+		if obj == nil {
+			t.Error("Expected a ray from x =", x, "to hit the second sphere")
+			continue
+		}
+		if want := 1 - x; math.Abs(dist-want) > 1e-3 {
+			t.Error("Expected a ray from x =", x, "to travel", want, "got", dist)
+		}
+	}
+}
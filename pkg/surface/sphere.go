@@ -28,21 +28,9 @@ func UnitSphere(m ...Material) *Sphere {
 	return s.transform(geom.Identity())
 }
 
-// TODO: unify with cube.transform AABB calc
 func (s *Sphere) transform(t *geom.Mtx) *Sphere {
 	s.mtx = s.mtx.Mult(t)
-	min := s.mtx.MultPoint(geom.Vec{})
-	max := s.mtx.MultPoint(geom.Vec{})
-	for x := -0.5; x <= 0.5; x += 1 {
-		for y := -0.5; y <= 0.5; y += 1 {
-			for z := -0.5; z <= 0.5; z += 1 {
-				pt := s.mtx.MultPoint(geom.Vec{x, y, z})
-				min = min.Min(pt)
-				max = max.Max(pt)
-			}
-		}
-	}
-	s.bounds = geom.NewBounds(min, max)
+	s.bounds = transformedBounds(s.mtx, geom.Vec{-0.5, -0.5, -0.5}, geom.Vec{0.5, 0.5, 0.5})
 	return s
 }
 
@@ -85,38 +73,69 @@ func (s *Sphere) Intersect(ray *geom.Ray, max float64) (obj render.Object, dist
 	t1 := b - root
 	if t1 > 0 {
 		dist := s.mtx.MultDist(r.Dir.Scaled(t1)).Len()
-		if dist > bias {
+		if dist > bias && dist < max {
 			return s, dist
 		}
 	}
 	t2 := b + root
 	if t2 > 0 {
 		dist := s.mtx.MultDist(r.Dir.Scaled(t2)).Len()
-		if dist > bias {
+		if dist > bias && dist < max {
 			return s, dist
 		}
 	}
 	return nil, 0
 }
 
+// Spans returns the span of the ray within the Sphere.
+func (s *Sphere) Spans(ray *geom.Ray) []Span {
+	r := s.mtx.Inverse().MultRay(ray)
+	op := geom.Vec{}.Minus(r.Origin)
+	b := op.Dot(geom.Vec(r.Dir))
+	det := b*b - op.Dot(op) + 0.5*0.5
+	if det < 0 {
+		return nil
+	}
+	root := math.Sqrt(det)
+	return convexSpans(s, s.mtx, r.Dir, []float64{b - root, b + root})
+}
+
 // At returns the surface normal given a point on the surface.
-func (s *Sphere) At(pt geom.Vec, in geom.Dir, rnd *rand.Rand) (normal geom.Dir, bsdf render.BSDF) {
+func (s *Sphere) At(pt geom.Vec, in geom.Dir, width float64, rnd *rand.Rand) (normal geom.Dir, bsdf render.BSDF) {
+	return shade(s.mat, s.frameAt(pt), in, width, rnd)
+}
+
+// frameAt maps longitude to u and latitude to v, from the bottom (v = 0) to the top (v = 1).
+// https://en.wikipedia.org/wiki/UV_mapping#Finding_UV_on_a_sphere
+func (s *Sphere) frameAt(pt geom.Vec) frame {
 	i := s.mtx.Inverse()
 	p := i.MultPoint(pt)
 	pu, _ := p.Unit()
-	n := s.mtx.MultDir(pu)
-	n2, bsdf := s.mat.At(0, 0, in, n, rnd)
-	_ = n2
-	normal = n // TODO: compute normal by combining n and n2 (and a bitangent)
-	return normal, bsdf
+	u := math.Atan2(p.X, p.Z)/(2*math.Pi) + 0.5
+	v := math.Acos(math.Max(-1, math.Min(1, -pu.Y))) / math.Pi
+	dpdu := geom.Vec{p.Z, 0, -p.X}.Scaled(2 * math.Pi)
+	dpdv := geom.Vec{}
+	if rad := math.Sqrt(p.X*p.X + p.Z*p.Z); rad > 0 {
+		dpdv = geom.Vec{-p.Y * p.X / rad, rad, -p.Y * p.Z / rad}.Scaled(math.Pi)
+	}
+	return localFrame(s.mtx, p, pu, u, v, dpdu, dpdv)
+}
+
+func (s *Sphere) material() Material {
+	return s.mat
 }
 
 func (s *Sphere) Light() rgb.Energy {
 	return s.mat.Light()
 }
 
-func (s *Sphere) Transmit() rgb.Energy {
-	return s.mat.Transmit()
+// LightAt returns the light emitted at a point on the Sphere.
+func (s *Sphere) LightAt(pt geom.Vec) rgb.Energy {
+	return emit(s.mat, s.frameAt(pt))
+}
+
+func (s *Sphere) Absorb() rgb.Energy {
+	return s.mat.Absorb()
 }
 
 func (s *Sphere) Lights() []render.Object {
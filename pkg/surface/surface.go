@@ -1,8 +1,12 @@
 package surface
 
 import (
+	"math"
+	"math/rand"
+
 	"github.com/hunterloftis/pbr/pkg/geom"
 	"github.com/hunterloftis/pbr/pkg/render"
+	"github.com/hunterloftis/pbr/pkg/texture"
 )
 
 // bias is the minimum distance unit.
@@ -19,3 +23,136 @@ func BoundsAround(ss []render.Surface) *geom.Bounds {
 	}
 	return Bounds
 }
+
+// transformedBounds returns the world-space Bounds around the local-space box min-max after transformation by mtx.
+func transformedBounds(mtx *geom.Mtx, min, max geom.Vec) *geom.Bounds {
+	lo := mtx.MultPoint(min)
+	hi := lo
+	for _, x := range [2]float64{min.X, max.X} {
+		for _, y := range [2]float64{min.Y, max.Y} {
+			for _, z := range [2]float64{min.Z, max.Z} {
+				pt := mtx.MultPoint(geom.Vec{x, y, z})
+				lo = lo.Min(pt)
+				hi = hi.Max(pt)
+			}
+		}
+	}
+	return geom.NewBounds(lo, hi)
+}
+
+// nearest converts local-space distances along dir into world-space distances
+// and returns the smallest that lies between bias and max.
+func nearest(mtx *geom.Mtx, dir geom.Dir, max float64, ts ...float64) (dist float64, ok bool) {
+	dist = max
+	for _, t := range ts {
+		if t <= 0 {
+			continue
+		}
+		d := mtx.MultDist(dir.Scaled(t)).Len()
+		if d > bias && d < dist {
+			dist, ok = d, true
+		}
+	}
+	return dist, ok
+}
+
+// frame describes the local geometry of a point on a Surface.
+// dpdu and dpdv are the rates of change of position with u and v;
+// they are zero on surfaces without a tangent frame.
+type frame struct {
+	normal     geom.Dir
+	u, v       float64
+	dpdu, dpdv geom.Vec
+	pos, local geom.Vec // position in world and object space
+	scale      float64  // world units per object unit
+}
+
+// localFrame returns a frame at local-space point pt from local-space geometry transformed by mtx.
+func localFrame(mtx *geom.Mtx, pt geom.Vec, normal geom.Dir, u, v float64, dpdu, dpdv geom.Vec) frame {
+	x, y, z := mtx.MultDist(geom.Vec{1, 0, 0}), mtx.MultDist(geom.Vec{0, 1, 0}), mtx.MultDist(geom.Vec{0, 0, 1})
+	return frame{
+		normal: mtx.MultDir(normal),
+		u:      u,
+		v:      v,
+		dpdu:   mtx.MultDist(dpdu),
+		dpdv:   mtx.MultDist(dpdv),
+		pos:    mtx.MultPoint(pt),
+		local:  pt,
+		scale:  math.Cbrt(math.Abs(x.Cross(y).Dot(z))),
+	}
+}
+
+// perturb converts a tangent-space normal n (in which geom.Up is the surface normal) into world space.
+// The result is discarded if in would strike it from the opposite side than the surface normal.
+// https://learnopengl.com/Advanced-Lighting/Normal-Mapping
+func (f frame) perturb(n, in geom.Dir) geom.Dir {
+	if n == geom.Up {
+		return f.normal
+	}
+	norm := geom.Vec(f.normal)
+	tan, ok := f.dpdu.Minus(norm.Scaled(f.dpdu.Dot(norm))).Unit()
+	if !ok {
+		return f.normal
+	}
+	bitan, _ := f.normal.Cross(tan)
+	if geom.Vec(bitan).Dot(f.dpdv) < 0 {
+		bitan = bitan.Inv()
+	}
+	world, ok := tan.Scaled(n.X).Plus(norm.Scaled(n.Y)).Plus(bitan.Scaled(n.Z)).Unit()
+	if !ok || (in.Dot(world) > 0) != (in.Dot(f.normal) > 0) {
+		return f.normal
+	}
+	return world
+}
+
+// shape is an Object whose geometry can be described independently of its material.
+type shape interface {
+	render.Object
+	frameAt(pt geom.Vec) frame
+	material() Material
+}
+
+// coord returns the texture coordinate at frame f of a ray footprint width wide.
+func (f frame) coord(width float64) texture.Coord {
+	c := texture.Coord{
+		U:      f.u,
+		V:      f.v,
+		World:  texture.Point{Pos: f.pos, Width: width},
+		Object: texture.Point{Pos: f.local, Width: width},
+	}
+	if scale := math.Sqrt(f.dpdu.Len() * f.dpdv.Len()); scale > 0 {
+		c.Width = width / scale
+	}
+	if f.scale > 0 {
+		c.Object.Width = width / f.scale
+	}
+	return c
+}
+
+// angle returns the angle of dpdu around normal, from the X axis toward the Z axis of the tangent space around normal.
+func (f frame) angle(normal geom.Dir) float64 {
+	to, _ := geom.Tangent(normal)
+	t := to.MultDist(f.dpdu)
+	return math.Atan2(t.Z, t.X)
+}
+
+// orienter is a BSDF, such as bsdf.Principled, that can follow the direction of increasing u.
+type orienter interface {
+	Oriented(angle float64) render.BSDF
+}
+
+// shade returns the normal and BSDF of material m at frame f, seen by a ray footprint width wide.
+// TwoSided materials struck from behind are shaded as if from the front, in a frame turned over toward the ray:
+// its normal and tangent are flipped, so normal maps keep their handedness on either side.
+func shade(m Material, f frame, in geom.Dir, width float64, rnd *rand.Rand) (geom.Dir, render.BSDF) {
+	if t, ok := m.(TwoSided); ok && t.TwoSided() && in.Dot(f.normal) > 0 {
+		f.normal = f.normal.Inv()
+		f.dpdu = f.dpdu.Scaled(-1)
+	}
+	n, bsdf := m.At(f.coord(width), in, f.normal, rnd)
+	normal := f.perturb(n, in)
+	if o, ok := bsdf.(orienter); ok {
+		bsdf = o.Oriented(f.angle(normal))
+	}
+	return normal, bsdf
+}
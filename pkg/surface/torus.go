@@ -0,0 +1,157 @@
+package surface
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/render"
+	"github.com/hunterloftis/pbr/pkg/rgb"
+)
+
+// Torus describes a ring around the Y axis with an outer diameter of 1
+type Torus struct {
+	major  float64
+	minor  float64
+	mtx    *geom.Mtx
+	mat    Material
+	bounds *geom.Bounds
+}
+
+// UnitTorus returns a pointer to a new Torus Surface with material and optional transforms.
+// minor is the radius of the tube (0 - 0.5); the ring fits within a 1x1 footprint.
+func UnitTorus(minor float64, m ...Material) *Torus {
+	minor = math.Max(0, math.Min(0.5, minor))
+	t := &Torus{
+		major: 0.5 - minor,
+		minor: minor,
+		mtx:   geom.Identity(),
+		mat:   &DefaultMaterial{},
+	}
+	if len(m) > 0 {
+		t.mat = m[0]
+	}
+	return t.transform(geom.Identity())
+}
+
+func (t *Torus) Intersect(ray *geom.Ray, max float64) (obj render.Object, dist float64) {
+	if ok, near, _ := t.bounds.Check(ray); !ok || near >= max {
+		return nil, 0
+	}
+	r := t.mtx.Inverse().MultRay(ray)
+	if dist, ok := nearest(t.mtx, r.Dir, max, t.crossings(r)...); ok {
+		return t, dist
+	}
+	return nil, 0
+}
+
+// Spans returns the (up to two) spans of the ray within the Torus.
+func (t *Torus) Spans(ray *geom.Ray) []Span {
+	r := t.mtx.Inverse().MultRay(ray)
+	ts := t.crossings(r)
+	spans := make([]Span, 0, len(ts)/2)
+	for i := 0; i+1 < len(ts); i += 2 {
+		spans = append(spans, Span{
+			In:  Hit{signedDist(t.mtx, r.Dir, ts[i]), t},
+			Out: Hit{signedDist(t.mtx, r.Dir, ts[i+1]), t},
+		})
+	}
+	return spans
+}
+
+// crossings solves the quartic (|p|^2 + R^2 - r^2)^2 = 4R^2(x^2 + z^2), returning local distances in order.
+// The ray is first advanced to the Torus' bounding sphere to keep the coefficients well-conditioned.
+// http://www.cosinekitty.com/raytrace/chapter13_torus.html
+func (t *Torus) crossings(r *geom.Ray) []float64 {
+	d := geom.Vec(r.Dir)
+	b := r.Origin.Dot(d)
+	det := b*b - r.Origin.Dot(r.Origin) + 0.25
+	if det < 0 {
+		return nil
+	}
+	start := -b - math.Sqrt(det)
+	o := r.Moved(start)
+	R2, r2 := t.major*t.major, t.minor*t.minor
+	od := o.Dot(d)
+	k := o.Dot(o) + R2 - r2
+	roots := solveQuartic(
+		4*od,
+		2*k+4*od*od-4*R2*(d.X*d.X+d.Z*d.Z),
+		4*k*od-8*R2*(o.X*d.X+o.Z*d.Z),
+		k*k-4*R2*(o.X*o.X+o.Z*o.Z),
+	)
+	for i := range roots {
+		roots[i] += start
+	}
+	return roots
+}
+
+// At returns the normal geom.Vec at this point on the Surface
+func (t *Torus) At(pt geom.Vec, in geom.Dir, width float64, rnd *rand.Rand) (normal geom.Dir, bsdf render.BSDF) {
+	return shade(t.mat, t.frameAt(pt), in, width, rnd)
+}
+
+func (t *Torus) frameAt(pt geom.Vec) frame {
+	p := t.mtx.Inverse().MultPoint(pt)
+	ring, ok := geom.Vec{p.X, 0, p.Z}.Unit()
+	if !ok {
+		ring = geom.Dir{1, 0, 0}
+	}
+	tube := p.Minus(ring.Scaled(t.major))
+	normal, _ := tube.Unit()
+	u := math.Atan2(p.X, p.Z)/(2*math.Pi) + 0.5
+	v := math.Atan2(tube.Y, geom.Vec(ring).Dot(tube))/(2*math.Pi) + 0.5
+	dpdu := geom.Vec{p.Z, 0, -p.X}.Scaled(2 * math.Pi)
+	dpdv := geom.Vec{0, geom.Vec(ring).Dot(tube), 0}.Minus(ring.Scaled(tube.Y)).Scaled(2 * math.Pi)
+	return localFrame(t.mtx, p, normal, u, v, dpdu, dpdv)
+}
+
+func (t *Torus) material() Material {
+	return t.mat
+}
+
+func (t *Torus) Bounds() *geom.Bounds {
+	return t.bounds
+}
+
+func (t *Torus) Lights() []render.Object {
+	if !t.mat.Light().Zero() {
+		return []render.Object{t}
+	}
+	return nil
+}
+
+func (t *Torus) Light() rgb.Energy {
+	return t.mat.Light()
+}
+
+// LightAt returns the light emitted at a point on the Torus.
+func (t *Torus) LightAt(pt geom.Vec) rgb.Energy {
+	return emit(t.mat, t.frameAt(pt))
+}
+
+func (t *Torus) Absorb() rgb.Energy {
+	return t.mat.Absorb()
+}
+
+func (t *Torus) Shift(v geom.Vec) *Torus {
+	return t.transform(geom.Shift(v))
+}
+
+func (t *Torus) Scale(v geom.Vec) *Torus {
+	return t.transform(geom.Scale(v))
+}
+
+func (t *Torus) Rotate(v geom.Vec) *Torus {
+	return t.transform(geom.Rotate(v))
+}
+
+func (t *Torus) Center() geom.Vec {
+	return t.mtx.MultPoint(geom.Vec{})
+}
+
+func (t *Torus) transform(m *geom.Mtx) *Torus {
+	t.mtx = t.mtx.Mult(m)
+	t.bounds = transformedBounds(t.mtx, geom.Vec{-0.5, -t.minor, -0.5}, geom.Vec{0.5, t.minor, 0.5})
+	return t
+}
@@ -16,6 +16,8 @@ type Triangle struct {
 	Mat     Material
 	edge1   geom.Vec
 	edge2   geom.Vec
+	dpdu    geom.Vec
+	dpdv    geom.Vec
 	bounds  *geom.Bounds
 }
 
@@ -51,6 +53,8 @@ func (t *Triangle) Transformed(mtx *geom.Mtx) *Triangle {
 	}
 	t2.edge1 = t2.Points[1].Minus(t2.Points[0])
 	t2.edge2 = t2.Points[2].Minus(t2.Points[0])
+	t2.dpdu = mtx.MultDist(t.dpdu)
+	t2.dpdv = mtx.MultDist(t.dpdv)
 	min := t2.Points[0].Min(t2.Points[1]).Min(t2.Points[2])
 	max := t2.Points[0].Max(t2.Points[1]).Max(t2.Points[2])
 	t2.bounds = geom.NewBounds(min, max)
@@ -90,16 +94,24 @@ func (t *Triangle) Intersect(ray *geom.Ray, max float64) (obj render.Object, dis
 }
 
 // At returns the material at a point on the Triangle
-// https://stackoverflow.com/questions/21210774/normal-mapping-on-procedural-sphere
-func (t *Triangle) At(pt geom.Vec, in geom.Dir, rnd *rand.Rand) (geom.Dir, render.BSDF) {
+func (t *Triangle) At(pt geom.Vec, in geom.Dir, width float64, rnd *rand.Rand) (geom.Dir, render.BSDF) {
+	return shade(t.Mat, t.frameAt(pt), in, width, rnd)
+}
+
+// frameAt uses world space as the Triangle's object space, since meshes are transformed into world space.
+func (t *Triangle) frameAt(pt geom.Vec) frame {
 	u, v, w := t.Bary(pt)
-	n := t.normal(u, v, w)
 	texture := t.texture(u, v, w)
-	n2, bsdf := t.Mat.At(texture.X, texture.Y, in, n, rnd)
-	// TODO: compute binormal and combine texture normal with n to return actual normal
-	_ = n2
-	normal := n
-	return normal, bsdf
+	return frame{
+		normal: t.normal(u, v, w),
+		u:      texture.X,
+		v:      texture.Y,
+		dpdu:   t.dpdu,
+		dpdv:   t.dpdv,
+		pos:    pt,
+		local:  pt,
+		scale:  1,
+	}
 }
 
 func (t *Triangle) Lights() []render.Object {
@@ -113,8 +125,13 @@ func (t *Triangle) Light() rgb.Energy {
 	return t.Mat.Light()
 }
 
-func (t *Triangle) Transmit() rgb.Energy {
-	return t.Mat.Transmit()
+// LightAt returns the light emitted at a point on the Triangle.
+func (t *Triangle) LightAt(pt geom.Vec) rgb.Energy {
+	return emit(t.Mat, t.frameAt(pt))
+}
+
+func (t *Triangle) Absorb() rgb.Energy {
+	return t.Mat.Absorb()
 }
 
 // SetNormals sets values for each vertex normal
@@ -124,10 +141,21 @@ func (t *Triangle) SetNormals(a, b, c geom.Dir) {
 	t.Normals[2] = c
 }
 
+// SetTexture sets texture coordinates for each vertex and computes the Triangle's tangents from them.
+// http://www.terathon.com/code/tangent.html
 func (t *Triangle) SetTexture(a, b, c geom.Vec) {
 	t.Texture[0] = a
 	t.Texture[1] = b
 	t.Texture[2] = c
+	duv1 := b.Minus(a)
+	duv2 := c.Minus(a)
+	det := duv1.X*duv2.Y - duv2.X*duv1.Y
+	if det == 0 {
+		t.dpdu, t.dpdv = geom.Vec{}, geom.Vec{}
+		return
+	}
+	t.dpdu = t.edge1.Scaled(duv2.Y).Minus(t.edge2.Scaled(duv1.Y)).Scaled(1 / det)
+	t.dpdv = t.edge2.Scaled(duv1.X).Minus(t.edge1.Scaled(duv2.X)).Scaled(1 / det)
 }
 
 // Normal computes the smoothed normal
@@ -0,0 +1,105 @@
+package texture
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"sync"
+
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "github.com/ftrvxmtrx/tga"
+)
+
+// Cache reads each image file once and shares its pixels between every Image of that file.
+type Cache struct {
+	Budget int // bytes of pixels to keep before evicting the finest mip levels of the largest images, or zero for no limit
+	mu     sync.Mutex
+	files  map[cacheKey]*pyramid
+}
+
+type cacheKey struct {
+	path  string
+	space ColorSpace
+}
+
+// Files is the process-wide Cache.
+var Files = NewCache()
+
+func NewCache() *Cache {
+	return &Cache{files: make(map[cacheKey]*pyramid)}
+}
+
+// ReadFile returns an Image of the file at filename, decoded from space.
+// Each Image has its own Transform and Sampler.
+func (c *Cache) ReadFile(filename string, space ColorSpace) (*Image, error) {
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	key := cacheKey{path, space}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.files[key]
+	if !ok {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		img, _, err := image.Decode(f)
+		if err != nil {
+			return nil, err
+		}
+		p = NewImage(img, space).pyramid
+		p.cache = c
+		c.files[key] = p
+		c.evict()
+	}
+	return &Image{Transform: Identity(), pyramid: p}, nil
+}
+
+// Size returns the memory used by the pixels of every image in c, in bytes.
+func (c *Cache) Size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size()
+}
+
+func (c *Cache) size() int {
+	n := 0
+	for _, p := range c.files {
+		n += p.size()
+	}
+	return n
+}
+
+// fit evicts mip levels until c is within its Budget.
+func (c *Cache) fit() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evict()
+}
+
+// evict drops the finest mip level of the largest image until c is within its Budget.
+// Images sampled after eviction lose detail rather than failing.
+func (c *Cache) evict() {
+	if c.Budget <= 0 {
+		return
+	}
+	for c.size() > c.Budget {
+		var largest *pyramid
+		pixels := 1
+		for _, p := range c.files {
+			if l := p.load()[0]; l.w*l.h > pixels {
+				largest, pixels = p, l.w*l.h
+			}
+		}
+		if largest == nil {
+			return
+		}
+		largest.once.Do(largest.mipmap)
+		largest.levels.Store(largest.load()[1:])
+	}
+}
@@ -0,0 +1,310 @@
+package texture
+
+import (
+	"image"
+	"math"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hunterloftis/pbr/pkg/rgb"
+)
+
+// Filter selects how an Image is sampled between pixels.
+type Filter int
+
+const (
+	Nearest   Filter = iota // the closest pixel
+	Bilinear                // the four closest pixels
+	Trilinear               // bilinear samples of the two mip levels closest to the footprint
+	EWA                     // a Gaussian-weighted average of the pixels under the footprint
+)
+
+// Wrap selects how an Image is sampled outside of 0-1.
+type Wrap int
+
+const (
+	Repeat Wrap = iota
+	Clamp       // to the edge pixels
+	Mirror      // repeat, flipping every other tile
+)
+
+// ColorSpace is the encoding of an image's values.
+type ColorSpace int
+
+const (
+	Linear ColorSpace = iota // data, such as roughness, normals, or heights
+	SRGB                     // color, encoded with the sRGB transfer function
+)
+
+// Sampler configures how an Image is sampled.
+type Sampler struct {
+	Filter Filter
+	Wrap   Wrap
+}
+
+// Image is a Texture backed by an image.
+// v increases from the bottom of the image to the top.
+// Images copied from one another share pixels, but not their Transform or Sampler.
+type Image struct {
+	Transform Transform
+	Sampler   Sampler
+	*pyramid
+}
+
+// pyramid holds an image as linear values in a mip pyramid.
+// The finest levels may be evicted by a Cache to save memory.
+type pyramid struct {
+	levels atomic.Value // []*level, from the finest available resolution down to 1x1
+	once   sync.Once
+	cache  *Cache // to account for memory after mipmapping, or nil
+}
+
+// level is one level of a mip pyramid, holding packed RGB values.
+type level struct {
+	w, h int
+	pix  []float32
+}
+
+// NewImage returns an Image of img, decoded from space into linear values.
+func NewImage(img image.Image, space ColorSpace) *Image {
+	p := &pyramid{}
+	p.levels.Store([]*level{newLevel(img, space)})
+	return &Image{Transform: Identity(), pyramid: p}
+}
+
+func newLevel(img image.Image, space ColorSpace) *level {
+	b := img.Bounds()
+	l := &level{w: b.Dx(), h: b.Dy()}
+	l.pix = make([]float32, 0, l.w*l.h*3)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			l.pix = append(l.pix, space.decode(r), space.decode(g), space.decode(b))
+		}
+	}
+	return l
+}
+
+// srgbTable holds the linear values of 8-bit sRGB values.
+var srgbTable = func() (t [256]float32) {
+	for i := range t {
+		t[i] = float32(srgbToLinear(float64(i) / 255))
+	}
+	return t
+}()
+
+// decode returns the linear value of the 16-bit channel value c.
+func (s ColorSpace) decode(c uint32) float32 {
+	if s == Linear {
+		return float32(c) / 65535
+	}
+	if c%0x101 == 0 { // expanded from 8 bits
+		return srgbTable[c/0x101]
+	}
+	return float32(srgbToLinear(float64(c) / 65535))
+}
+
+// https://en.wikipedia.org/wiki/SRGB#Transformation
+func srgbToLinear(c float64) float64 {
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+// Size returns the width and height of the Image in pixels.
+func (im *Image) Size() (w, h int) {
+	l := im.load()[0]
+	return l.w, l.h
+}
+
+func (im *Image) At(c Coord) rgb.Energy {
+	c = im.Transform.Apply(c)
+	switch im.Sampler.Filter {
+	case Nearest:
+		return im.nearest(im.load()[0], c)
+	case Bilinear:
+		return im.bilinear(im.load()[0], c)
+	}
+	levels := im.mipmapped()
+	lod := mipLevel(levels, c.Width)
+	if im.Sampler.Filter == EWA {
+		return im.ewa(levels[int(math.Max(0, math.Floor(lod)-1))], c)
+	}
+	// https://en.wikipedia.org/wiki/Trilinear_filtering
+	i := int(lod)
+	e := im.bilinear(levels[i], c)
+	if f := lod - float64(i); f > 0 {
+		e = e.Lerp(im.bilinear(levels[i+1], c), f)
+	}
+	return e
+}
+
+// mipLevel returns the fractional mip level at which a footprint width wide covers about one pixel.
+func mipLevel(levels []*level, width float64) float64 {
+	size := float64(levels[0].w)
+	if h := float64(levels[0].h); h > size {
+		size = h
+	}
+	lod := math.Log2(width * size)
+	if lod <= 0 || math.IsNaN(lod) {
+		return 0
+	}
+	return math.Min(lod, float64(len(levels)-1))
+}
+
+func (p *pyramid) load() []*level {
+	return p.levels.Load().([]*level)
+}
+
+// mipmapped returns every level of p, building them on first use.
+func (p *pyramid) mipmapped() []*level {
+	built := false
+	p.once.Do(func() {
+		p.mipmap()
+		built = true
+	})
+	if built && p.cache != nil {
+		p.cache.fit()
+	}
+	return p.load()
+}
+
+// mipmap builds the mip pyramid by averaging 2x2 blocks of pixels.
+// Along odd dimensions, the last pixel of each level averages three, so the odd row or column isn't dropped.
+// https://en.wikipedia.org/wiki/Mipmap
+func (p *pyramid) mipmap() {
+	levels := p.load()
+	for l := levels[0]; l.w > 1 || l.h > 1; {
+		next := &level{w: clamp(l.w/2, 1, l.w), h: clamp(l.h/2, 1, l.h)}
+		next.pix = make([]float32, 0, next.w*next.h*3)
+		for y := 0; y < next.h; y++ {
+			ys := taps(y, l.h)
+			for x := 0; x < next.w; x++ {
+				xs := taps(x, l.w)
+				var e rgb.Energy
+				for _, ty := range ys {
+					for _, tx := range xs {
+						e = e.Plus(l.at(tx, ty))
+					}
+				}
+				e = e.Scaled(1 / float64(len(xs)*len(ys)))
+				next.pix = append(next.pix, float32(e.X), float32(e.Y), float32(e.Z))
+			}
+		}
+		levels = append(levels, next)
+		l = next
+	}
+	p.levels.Store(levels)
+}
+
+// taps returns the pixels, of a row or column n pixels long, that pixel i of the next level averages.
+func taps(i, n int) []int {
+	switch {
+	case n == 1:
+		return []int{0}
+	case n%2 == 1 && i == n/2-1:
+		return []int{i * 2, i*2 + 1, i*2 + 2}
+	}
+	return []int{i * 2, i*2 + 1}
+}
+
+// size returns the memory used by p's pixels, in bytes.
+func (p *pyramid) size() int {
+	n := 0
+	for _, l := range p.load() {
+		n += len(l.pix) * 4
+	}
+	return n
+}
+
+func (im *Image) nearest(l *level, c Coord) rgb.Energy {
+	x, y := l.coords(c)
+	return im.pixel(l, int(math.Floor(x)), int(math.Floor(y)))
+}
+
+// https://en.wikipedia.org/wiki/Bilinear_filtering
+func (im *Image) bilinear(l *level, c Coord) rgb.Energy {
+	x, y := l.coords(c)
+	x, y = x-0.5, y-0.5
+	x0, y0 := math.Floor(x), math.Floor(y)
+	fx, fy := x-x0, y-y0
+	ix, iy := int(x0), int(y0)
+	top := im.pixel(l, ix, iy).Lerp(im.pixel(l, ix+1, iy), fx)
+	bottom := im.pixel(l, ix, iy+1).Lerp(im.pixel(l, ix+1, iy+1), fx)
+	return top.Lerp(bottom, fy)
+}
+
+// ewa returns the Gaussian-weighted average of the pixels of l under the footprint of c.
+// Footprints are isotropic, so the ellipse is a circle.
+// https://www.pbr-book.org/3ed-2018/Texture/Image_Texture#EllipticallyWeightedAverage
+func (im *Image) ewa(l *level, c Coord) rgb.Energy {
+	const alpha = 2
+	x, y := l.coords(c)
+	r := math.Max(1, c.Width*0.5*math.Max(float64(l.w), float64(l.h)))
+	var sum rgb.Energy
+	total := 0.0
+	for py := int(math.Floor(y - r)); py <= int(math.Ceil(y+r)); py++ {
+		for px := int(math.Floor(x - r)); px <= int(math.Ceil(x+r)); px++ {
+			dx, dy := float64(px)+0.5-x, float64(py)+0.5-y
+			d2 := (dx*dx + dy*dy) / (r * r)
+			if d2 >= 1 {
+				continue
+			}
+			w := math.Exp(-alpha*d2) - math.Exp(-alpha)
+			sum = sum.Plus(im.pixel(l, px, py).Scaled(w))
+			total += w
+		}
+	}
+	if total == 0 {
+		return im.bilinear(l, c)
+	}
+	return sum.Scaled(1 / total)
+}
+
+// pixel returns the value of pixel x, y of l, wrapped by the Sampler.
+func (im *Image) pixel(l *level, x, y int) rgb.Energy {
+	return l.at(im.Sampler.Wrap.apply(x, l.w), im.Sampler.Wrap.apply(y, l.h))
+}
+
+// coords returns the position of c in pixels.
+func (l *level) coords(c Coord) (x, y float64) {
+	return c.U * float64(l.w), (1 - c.V) * float64(l.h)
+}
+
+func (l *level) at(x, y int) rgb.Energy {
+	i := (y*l.w + x) * 3
+	return rgb.Energy{float64(l.pix[i]), float64(l.pix[i+1]), float64(l.pix[i+2])}
+}
+
+// apply maps pixel index i into 0 to n-1.
+func (w Wrap) apply(i, n int) int {
+	switch w {
+	case Clamp:
+		return clamp(i, 0, n-1)
+	case Mirror:
+		i %= 2 * n
+		if i < 0 {
+			i += 2 * n
+		}
+		if i >= n {
+			i = 2*n - 1 - i
+		}
+		return i
+	}
+	i %= n
+	if i < 0 {
+		i += n
+	}
+	return i
+}
+
+func clamp(i, lo, hi int) int {
+	if i < lo {
+		return lo
+	}
+	if i > hi {
+		return hi
+	}
+	return i
+}
@@ -0,0 +1,39 @@
+package texture
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestMipmapOddSize(t *testing.T) {
+	// a 5x3 image, black but for its last column and row, which a 2x2 average would drop
+	img := image.NewRGBA(image.Rect(0, 0, 5, 3))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 5; x++ {
+			c := color.RGBA{0, 0, 0, 255}
+			if x == 4 || y == 2 {
+				c = color.RGBA{255, 255, 255, 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	im := NewImage(img, Linear)
+	levels := im.mipmapped()
+	if len(levels) != 3 {
+		t.Fatal("Expected levels of 5x3, 2x1 and 1x1, got", len(levels))
+	}
+	if l := levels[1]; l.w != 2 || l.h != 1 {
+		t.Fatal("Expected a 2x1 level, got", l.w, "x", l.h)
+	}
+	// the last column and row are a third of each average of three
+	for x, want := range []float64{1.0 / 3, 5.0 / 9} {
+		if e := levels[1].at(x, 0); math.Abs(e.X-want) > 1e-6 {
+			t.Error("Expected pixel", x, "to be", want, "got", e.X)
+		}
+	}
+	if e := levels[2].at(0, 0); math.Abs(e.X-4.0/9) > 1e-6 {
+		t.Error("Expected the 1x1 level to be", 4.0/9, "got", e.X)
+	}
+}
@@ -0,0 +1,118 @@
+package texture
+
+import (
+	"math"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+)
+
+// turbulenceMean approximates the mean of the absolute value of an octave of noise.
+const turbulenceMean = 0.25
+
+// hash returns a pseudorandom value for the lattice point x, y, z.
+func hash(x, y, z int) uint32 {
+	h := uint32(x)*73856093 ^ uint32(y)*19349663 ^ uint32(z)*83492791
+	h ^= h >> 13
+	h *= 0x5bd1e995
+	h ^= h >> 15
+	return h
+}
+
+// wrap maps lattice index i into 0 to period-1, or leaves it alone if period is not positive.
+func wrap(i, period int) int {
+	if period <= 0 {
+		return i
+	}
+	i %= period
+	if i < 0 {
+		i += period
+	}
+	return i
+}
+
+// perlin returns gradient noise at p, from about -1 to 1.
+// If period is positive, the noise repeats every period units along x and y.
+// https://mrl.cs.nyu.edu/~perlin/noise/
+func perlin(p geom.Vec, period int) float64 {
+	x0, y0, z0 := math.Floor(p.X), math.Floor(p.Y), math.Floor(p.Z)
+	x, y, z := p.X-x0, p.Y-y0, p.Z-z0
+	ix, iy, iz := int(x0), int(y0), int(z0)
+	corner := func(dx, dy, dz int) float64 {
+		h := hash(wrap(ix+dx, period), wrap(iy+dy, period), iz+dz)
+		return grad(h, x-float64(dx), y-float64(dy), z-float64(dz))
+	}
+	u, v, w := fade(x), fade(y), fade(z)
+	return lerp(w,
+		lerp(v, lerp(u, corner(0, 0, 0), corner(1, 0, 0)), lerp(u, corner(0, 1, 0), corner(1, 1, 0))),
+		lerp(v, lerp(u, corner(0, 0, 1), corner(1, 0, 1)), lerp(u, corner(0, 1, 1), corner(1, 1, 1))),
+	)
+}
+
+// grad returns the dot product of x, y, z with one of twelve gradients selected by h.
+func grad(h uint32, x, y, z float64) float64 {
+	h &= 15
+	u, v := x, z
+	if h >= 8 {
+		u = y
+	}
+	if h < 4 {
+		v = y
+	} else if h == 12 || h == 14 {
+		v = x
+	}
+	if h&1 != 0 {
+		u = -u
+	}
+	if h&2 != 0 {
+		v = -v
+	}
+	return u + v
+}
+
+func fade(t float64) float64 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+// fractal sums octaves of perlin noise at p, each at twice the frequency and gain times the amplitude of the last,
+// normalized to about -1 to 1 (or 0 to 1 if abs).
+// Octaves finer than the footprint width are replaced with their mean, the last one fading out gradually.
+// https://www.pbr-book.org/3ed-2018/Texture/Noise#FractionalBrownianMotion
+func fractal(p geom.Vec, width float64, octaves int, gain float64, period int, abs bool) float64 {
+	limit := float64(octaves)
+	if width > 0 {
+		limit = math.Max(0, math.Min(limit, -1-math.Log2(width)))
+	}
+	mean := 0.0
+	if abs {
+		mean = turbulenceMean
+	}
+	sum, total, amp := 0.0, 0.0, 1.0
+	for i := 0; i < octaves; i++ {
+		n := mean
+		if f := limit - float64(i); f > 0 {
+			o := perlin(p, period)
+			if abs {
+				o = math.Abs(o)
+			}
+			n = lerp(smoothstep(0.3, 0.7, f), mean, o)
+		}
+		sum += n * amp
+		total += amp
+		amp *= gain
+		p = p.Scaled(2)
+		period *= 2
+	}
+	if total == 0 {
+		return mean
+	}
+	return sum / total
+}
+
+func lerp(t, a, b float64) float64 {
+	return a + (b-a)*t
+}
+
+func smoothstep(lo, hi, x float64) float64 {
+	t := math.Max(0, math.Min(1, (x-lo)/(hi-lo)))
+	return t * t * (3 - 2*t)
+}
@@ -0,0 +1,266 @@
+package texture
+
+import (
+	"math"
+	"sort"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/rgb"
+)
+
+// Space selects the coordinates in which a procedural Texture is evaluated.
+type Space int
+
+const (
+	UV     Space = iota // u, v, 0; Checker, Noise, and Voronoi tile the unit square when their Scale is a whole number
+	Object              // the surface's untransformed position
+	World               // the surface's position in the scene
+)
+
+// point returns the position of c in s, with the diameter of its footprint.
+func (s Space) point(c Coord) (geom.Vec, float64) {
+	switch s {
+	case Object:
+		return c.Object.Pos, c.Object.Width
+	case World:
+		return c.World.Pos, c.World.Width
+	}
+	return geom.Vec{c.U, c.V, 0}, c.Width
+}
+
+// period returns the lattice period at which a pattern in s at scale tiles, or zero if it doesn't.
+func (s Space) period(scale float64) int {
+	if s != UV || scale != math.Floor(scale) {
+		return 0
+	}
+	return int(scale)
+}
+
+// Checker alternates between A and B in cubes 1/Scale wide.
+// It fades to the average of A and B as the footprint grows to a cube.
+type Checker struct {
+	A, B  Texture
+	Scale float64
+	Space Space
+}
+
+func NewChecker(a, b Texture, scale float64) *Checker {
+	return &Checker{A: a, B: b, Scale: scale}
+}
+
+func (ch *Checker) At(c Coord) rgb.Energy {
+	p, w := ch.Space.point(c)
+	p, w = p.Scaled(ch.Scale), w*ch.Scale
+	a, b := ch.A.At(c), ch.B.At(c)
+	e := a
+	if int(math.Floor(p.X)+math.Floor(p.Y)+math.Floor(p.Z))&1 != 0 {
+		e = b
+	}
+	return e.Lerp(a.Plus(b).Scaled(0.5), smoothstep(0.5, 1, w))
+}
+
+// Noise is fractional Brownian motion: Octaves of Perlin noise,
+// each at twice the frequency and Gain times the amplitude of the last.
+// It returns values from about 0 to 1, with a mean of 0.5.
+// Turbulence sums the absolute value of each octave instead, for billowing patterns with sharp creases.
+// https://en.wikipedia.org/wiki/Perlin_noise
+type Noise struct {
+	Scale      float64
+	Octaves    int
+	Gain       float64
+	Turbulence bool
+	Space      Space
+}
+
+func NewNoise(scale float64, octaves int) *Noise {
+	return &Noise{Scale: scale, Octaves: octaves, Gain: 0.5}
+}
+
+func NewTurbulence(scale float64, octaves int) *Noise {
+	return &Noise{Scale: scale, Octaves: octaves, Gain: 0.5, Turbulence: true}
+}
+
+func (n *Noise) At(c Coord) rgb.Energy {
+	return rgb.Energy(Scalar(n.value(c)))
+}
+
+func (n *Noise) value(c Coord) float64 {
+	p, w := n.Space.point(c)
+	v := fractal(p.Scaled(n.Scale), w*n.Scale, n.Octaves, n.Gain, n.Space.period(n.Scale), n.Turbulence)
+	if n.Turbulence {
+		return v
+	}
+	return v*0.5 + 0.5
+}
+
+// Feature selects the value of a Voronoi Texture.
+type Feature int
+
+const (
+	Distance Feature = iota // distance to the nearest feature point
+	Cell                    // a random value for each cell
+	Border                  // difference in distance to the two nearest feature points, zero along cell borders
+)
+
+// Voronoi is cellular noise around one randomly placed feature point per cube 1/Scale wide.
+// Jitter, from 0 to 1, controls how far feature points stray from the centers of their cubes.
+// https://en.wikipedia.org/wiki/Worley_noise
+type Voronoi struct {
+	Scale   float64
+	Jitter  float64
+	Feature Feature
+	Space   Space
+}
+
+func NewVoronoi(scale float64, feature Feature) *Voronoi {
+	return &Voronoi{Scale: scale, Jitter: 1, Feature: feature}
+}
+
+func (vo *Voronoi) At(c Coord) rgb.Energy {
+	p, _ := vo.Space.point(c)
+	p = p.Scaled(vo.Scale)
+	period := vo.Space.period(vo.Scale)
+	x0, y0, z0 := int(math.Floor(p.X)), int(math.Floor(p.Y)), int(math.Floor(p.Z))
+	d1, d2 := math.Inf(1), math.Inf(1)
+	var cell uint32
+	for x := x0 - 1; x <= x0+1; x++ {
+		for y := y0 - 1; y <= y0+1; y++ {
+			for z := z0 - 1; z <= z0+1; z++ {
+				h := hash(wrap(x, period), wrap(y, period), z)
+				offset := geom.Vec{unit(h), unit(h >> 10), unit(h >> 20)}.Minus(geom.Vec{0.5, 0.5, 0.5})
+				feature := geom.Vec{float64(x) + 0.5, float64(y) + 0.5, float64(z) + 0.5}.Plus(offset.Scaled(vo.Jitter))
+				d := feature.Minus(p).Len()
+				if d < d1 {
+					d1, d2, cell = d, d1, h
+				} else if d < d2 {
+					d2 = d
+				}
+			}
+		}
+	}
+	switch vo.Feature {
+	case Cell:
+		return rgb.Energy(Scalar(unit(cell * 0x9e3779b1)))
+	case Border:
+		return rgb.Energy(Scalar(math.Min(1, d2-d1)))
+	}
+	return rgb.Energy(Scalar(math.Min(1, d1)))
+}
+
+// unit maps the low 10 bits of h onto 0 to 1.
+func unit(h uint32) float64 {
+	return float64(h&1023) / 1023
+}
+
+// Wood is concentric growth rings around the y axis, Scale rings per unit, warped by turbulence.
+// It fades to the average of Light and Dark as the footprint grows to a ring.
+type Wood struct {
+	Light, Dark Texture
+	Scale       float64
+	Distortion  float64
+	Space       Space
+}
+
+func NewWood(light, dark rgb.Energy, scale float64) *Wood {
+	return &Wood{
+		Light:      Constant(light),
+		Dark:       Constant(dark),
+		Scale:      scale,
+		Distortion: 0.5,
+		Space:      Object,
+	}
+}
+
+func (wd *Wood) At(c Coord) rgb.Energy {
+	p, w := wd.Space.point(c)
+	p, w = p.Scaled(wd.Scale), w*wd.Scale
+	r := math.Hypot(p.X, p.Z) + wd.Distortion*fractal(p.Scaled(0.5), w*0.5, 4, 0.5, 0, true)
+	ring := math.Pow(0.5+0.5*math.Cos(2*math.Pi*r), 3)
+	ring = lerp(smoothstep(0.25, 1, w), ring, 0.3125) // the mean of cos^3
+	return wd.Light.At(c).Lerp(wd.Dark.At(c), ring)
+}
+
+// Marble is veins of Vein through Base, in bands along x, Scale per unit, warped by turbulence.
+// The veins fade out as the footprint grows to a band.
+type Marble struct {
+	Base, Vein Texture
+	Scale      float64
+	Distortion float64
+	Space      Space
+}
+
+func NewMarble(base, vein rgb.Energy, scale float64) *Marble {
+	return &Marble{
+		Base:       Constant(base),
+		Vein:       Constant(vein),
+		Scale:      scale,
+		Distortion: 4,
+		Space:      Object,
+	}
+}
+
+// https://www.pbr-book.org/3ed-2018/Texture/Noise#Marble
+func (m *Marble) At(c Coord) rgb.Energy {
+	p, w := m.Space.point(c)
+	p, w = p.Scaled(m.Scale), w*m.Scale
+	t := math.Sin(math.Pi * (p.X + m.Distortion*fractal(p, w, 6, 0.5, 0, true)))
+	vein := math.Pow(1-math.Abs(t), 8)
+	vein = lerp(smoothstep(0.25, 1, w), vein, 0.0714) // the mean of (1 - |sin|)^8
+	return m.Base.At(c).Lerp(m.Vein.At(c), vein)
+}
+
+// Gradient is a scalar from 0 at From to 1 at To, along the line between them,
+// or outward from From if Radial.
+type Gradient struct {
+	From, To geom.Vec
+	Radial   bool
+	Space    Space
+}
+
+func (g *Gradient) At(c Coord) rgb.Energy {
+	p, _ := g.Space.point(c)
+	axis := g.To.Minus(g.From)
+	len2 := axis.Dot(axis)
+	if len2 == 0 {
+		return rgb.Black
+	}
+	rel := p.Minus(g.From)
+	t := rel.Dot(axis) / len2
+	if g.Radial {
+		t = math.Sqrt(rel.Dot(rel) / len2)
+	}
+	return rgb.Energy(Scalar(math.Max(0, math.Min(1, t))))
+}
+
+// Ramp maps the mean of Input onto colors interpolated between Stops.
+type Ramp struct {
+	Input Texture
+	Stops []Stop // in increasing order of Pos
+}
+
+// Stop is a color at a position along a Ramp.
+type Stop struct {
+	Pos   float64
+	Color rgb.Energy
+}
+
+func NewRamp(input Texture, stops ...Stop) *Ramp {
+	sort.Slice(stops, func(i, j int) bool { return stops[i].Pos < stops[j].Pos })
+	return &Ramp{Input: input, Stops: stops}
+}
+
+func (r *Ramp) At(c Coord) rgb.Energy {
+	if len(r.Stops) == 0 {
+		return rgb.Black
+	}
+	t := r.Input.At(c).Mean()
+	i := sort.Search(len(r.Stops), func(i int) bool { return r.Stops[i].Pos > t })
+	if i == 0 {
+		return r.Stops[0].Color
+	}
+	if i == len(r.Stops) {
+		return r.Stops[i-1].Color
+	}
+	a, b := r.Stops[i-1], r.Stops[i]
+	return a.Color.Lerp(b.Color, (t-a.Pos)/(b.Pos-a.Pos))
+}
@@ -0,0 +1,73 @@
+// Package texture implements sources of color and scalar values that vary across a surface.
+package texture
+
+import (
+	"math"
+
+	"github.com/hunterloftis/pbr/pkg/geom"
+	"github.com/hunterloftis/pbr/pkg/rgb"
+)
+
+// Coord locates a sample on a surface.
+// Width is the diameter of the sample's footprint in texture coordinates, or zero for a point sample.
+type Coord struct {
+	U, V   float64
+	Width  float64
+	World  Point
+	Object Point
+}
+
+// Point locates a sample in 3D, with the diameter of its footprint in the same units.
+type Point struct {
+	Pos   geom.Vec
+	Width float64
+}
+
+// Texture returns a value at a texture coordinate.
+// Scalar textures return the same value in every channel.
+type Texture interface {
+	At(c Coord) rgb.Energy
+}
+
+// Constant is a Texture with the same value everywhere.
+type Constant rgb.Energy
+
+// Scalar returns a Constant with the value n in every channel.
+func Scalar(n float64) Constant {
+	return Constant{n, n, n}
+}
+
+func (c Constant) At(Coord) rgb.Energy {
+	return rgb.Energy(c)
+}
+
+// Func is a procedural Texture.
+type Func func(c Coord) rgb.Energy
+
+func (f Func) At(c Coord) rgb.Energy {
+	return f(c)
+}
+
+// Transform maps surface texture coordinates onto a texture
+// by scaling, then rotating counter-clockwise, then offsetting.
+type Transform struct {
+	Scale    geom.Vec // X scales u, Y scales v
+	Offset   geom.Vec
+	Rotation float64 // radians
+}
+
+// Identity returns a Transform that leaves coordinates unchanged.
+func Identity() Transform {
+	return Transform{Scale: geom.Vec{1, 1, 1}}
+}
+
+func (t Transform) Apply(c Coord) Coord {
+	u, v := c.U*t.Scale.X, c.V*t.Scale.Y
+	if t.Rotation != 0 {
+		sin, cos := math.Sincos(t.Rotation)
+		u, v = u*cos-v*sin, u*sin+v*cos
+	}
+	c.U, c.V = u+t.Offset.X, v+t.Offset.Y
+	c.Width *= math.Sqrt(math.Abs(t.Scale.X * t.Scale.Y))
+	return c
+}